@@ -0,0 +1,349 @@
+package grpcerr
+
+import (
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Builder is used to construct a gRPC error with any combination of error details in a single
+// chain of method calls, ending with a call to Err(). Use New() to instantiate it.
+//
+// Example:
+//
+//	err := grpcerr.New(codes.NotFound).
+//	    Message("user not found").
+//	    ResourceInfo(&grpcerr.ResourceInfo{ResourceType: "user", ResourceName: "john.doe"}).
+//	    RequestInfo(&grpcerr.RequestInfo{RequestID: requestID}).
+//	    Err()
+type Builder struct {
+	code    codes.Code
+	msg     string
+	details []*anypb.Any
+	cause   error
+	// err is the first error that occurred while adding details. Once set, all subsequent
+	// details are ignored and Err() returns an Internal gRPC error.
+	err error
+}
+
+// New returns a Builder for a gRPC error with the given code. If no message is set using
+// Message(), the default message of the code is used.
+func New(code codes.Code) *Builder {
+	return &Builder{code: code}
+}
+
+// Message sets the error message. If errMsg is empty the default message of the code is used.
+func (b *Builder) Message(errMsg string) *Builder {
+	b.msg = errMsg
+	return b
+}
+
+// Cause sets the Go error that caused the gRPC error. It's returned by the Unwrap() method of
+// the *Error returned by Err(), but it's never sent to clients.
+func (b *Builder) Cause(err error) *Builder {
+	b.cause = err
+	return b
+}
+
+// ErrorInfo adds an ErrorInfo detail. A nil errorInfo is ignored.
+func (b *Builder) ErrorInfo(errorInfo *ErrorInfo) *Builder {
+	if errorInfo == nil {
+		return b
+	}
+
+	return b.detail(&errdetails.ErrorInfo{
+		Reason:   errorInfo.Reason,
+		Domain:   errorInfo.Domain,
+		Metadata: errorInfo.Metadata,
+	})
+}
+
+// FieldViolations adds a BadRequest detail. An empty slice is ignored.
+func (b *Builder) FieldViolations(fieldViolations []FieldViolation) *Builder {
+	if len(fieldViolations) == 0 {
+		return b
+	}
+
+	badRequestDetails := &errdetails.BadRequest{}
+	for _, violation := range fieldViolations {
+		fv := &errdetails.BadRequest_FieldViolation{
+			Field:       violation.Field,
+			Description: violation.Description,
+		}
+		badRequestDetails.FieldViolations = append(badRequestDetails.FieldViolations, fv)
+	}
+
+	return b.detail(badRequestDetails)
+}
+
+// PreconditionFailures adds a PreconditionFailure detail. An empty slice is ignored.
+func (b *Builder) PreconditionFailures(failures []PreconditionFailure) *Builder {
+	if len(failures) == 0 {
+		return b
+	}
+
+	preconditionFailureDetails := &errdetails.PreconditionFailure{}
+	for _, failure := range failures {
+		v := &errdetails.PreconditionFailure_Violation{
+			Type:        failure.Type,
+			Subject:     failure.Subject,
+			Description: failure.Description,
+		}
+		preconditionFailureDetails.Violations = append(preconditionFailureDetails.Violations, v)
+	}
+
+	return b.detail(preconditionFailureDetails)
+}
+
+// QuotaViolations adds a QuotaFailure detail. An empty slice is ignored.
+func (b *Builder) QuotaViolations(violations []QuotaViolation) *Builder {
+	if len(violations) == 0 {
+		return b
+	}
+
+	quotaFailureDetails := &errdetails.QuotaFailure{}
+	for _, violation := range violations {
+		v := &errdetails.QuotaFailure_Violation{
+			Subject:     violation.Subject,
+			Description: violation.Description,
+		}
+		quotaFailureDetails.Violations = append(quotaFailureDetails.Violations, v)
+	}
+
+	return b.detail(quotaFailureDetails)
+}
+
+// ResourceInfo adds a ResourceInfo detail. A nil resourceInfo is ignored.
+func (b *Builder) ResourceInfo(resourceInfo *ResourceInfo) *Builder {
+	if resourceInfo == nil {
+		return b
+	}
+
+	return b.detail(&errdetails.ResourceInfo{
+		ResourceType: resourceInfo.ResourceType,
+		ResourceName: resourceInfo.ResourceName,
+		Owner:        resourceInfo.Owner,
+		Description:  resourceInfo.Description,
+	})
+}
+
+// RequestInfo adds a RequestInfo detail. A nil requestInfo is ignored.
+func (b *Builder) RequestInfo(requestInfo *RequestInfo) *Builder {
+	if requestInfo == nil {
+		return b
+	}
+
+	return b.detail(&errdetails.RequestInfo{
+		RequestId:   requestInfo.RequestID,
+		ServingData: requestInfo.ServingData,
+	})
+}
+
+// DebugInfo adds a DebugInfo detail. A nil debugInfo is ignored.
+func (b *Builder) DebugInfo(debugInfo *DebugInfo) *Builder {
+	if debugInfo == nil {
+		return b
+	}
+
+	return b.detail(&errdetails.DebugInfo{
+		StackEntries: debugInfo.StackEntries,
+		Detail:       debugInfo.Detail,
+	})
+}
+
+// RetryInfo adds a RetryInfo detail. A nil retryInfo is ignored.
+func (b *Builder) RetryInfo(retryInfo *RetryInfo) *Builder {
+	if retryInfo == nil {
+		return b
+	}
+
+	return b.detail(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(retryInfo.RetryDelay),
+	})
+}
+
+// Help adds a Help detail with the given links. An empty slice is ignored.
+func (b *Builder) Help(links []HelpLink) *Builder {
+	if len(links) == 0 {
+		return b
+	}
+
+	helpDetails := &errdetails.Help{}
+	for _, link := range links {
+		l := &errdetails.Help_Link{
+			Description: link.Description,
+			Url:         link.URL,
+		}
+		helpDetails.Links = append(helpDetails.Links, l)
+	}
+
+	return b.detail(helpDetails)
+}
+
+// LocalizedMessage adds a LocalizedMessage detail. A nil localizedMsg is ignored.
+func (b *Builder) LocalizedMessage(localizedMsg *LocalizedMessage) *Builder {
+	if localizedMsg == nil {
+		return b
+	}
+
+	return b.detail(&errdetails.LocalizedMessage{
+		Locale:  localizedMsg.Locale,
+		Message: localizedMsg.Message,
+	})
+}
+
+// detail marshals the detail and appends it to the builder's details.
+func (b *Builder) detail(detail proto.Message) *Builder {
+	if b.err != nil {
+		return b
+	}
+
+	anyDetail, err := anypb.New(detail)
+	if err != nil {
+		b.err = fmt.Errorf("could not marshal error detail: %w", err)
+		return b
+	}
+	b.details = append(b.details, anyDetail)
+
+	return b
+}
+
+// Status returns the gRPC status built so far. If adding any of the details failed, an
+// Internal status describing the failure is returned instead.
+func (b *Builder) Status() *status.Status {
+	return b.statusWith(b.details)
+}
+
+func (b *Builder) statusWith(details []*anypb.Any) *status.Status {
+	if b.err != nil {
+		return status.New(codes.Internal, b.err.Error())
+	}
+
+	msg := b.msg
+	if msg == "" {
+		msg = defaultErrMsg(b.code)
+	}
+
+	return status.FromProto(&spb.Status{
+		Code:    int32(b.code),
+		Message: msg,
+		Details: details,
+	})
+}
+
+// Err returns the gRPC error built so far as an *Error. If adding any of the details failed, an
+// Internal gRPC error describing the failure is returned instead. If the code is codes.OK, nil
+// is returned.
+//
+// If stack capture is enabled using EnableStackCapture and the code is Internal, Unknown or DataLoss,
+// the stack is captured into the DebugInfo, which is added if there isn't any. A DebugInfo with stack
+// entries is never overwritten.
+func (b *Builder) Err() error {
+	return b.errWith(b.detailsWithStack(0))
+}
+
+// errWithoutStack returns the gRPC error built so far like Err does, but never captures the stack. It's
+// used for errors which aren't created by the caller, such as decoded or masked errors.
+func (b *Builder) errWithoutStack() error {
+	return b.errWith(b.details)
+}
+
+func (b *Builder) errWith(details []*anypb.Any) error {
+	st := b.statusWith(details)
+	if st.Code() == codes.OK {
+		return nil
+	}
+
+	return &Error{st: st, cause: b.cause}
+}
+
+// detailsWithStack returns a copy of the details with the stack captured into the DebugInfo, or into an
+// added DebugInfo if there isn't any, if the code is a server fault and stack capture is enabled and
+// sampled. Otherwise, or if the DebugInfo already has stack entries, the details are returned as is.
+// skip is the number of frames between detailsWithStack and the exported function.
+func (b *Builder) detailsWithStack(skip int) []*anypb.Any {
+	if b.err != nil || !capturesStack(b.code) {
+		return b.details
+	}
+
+	index := -1
+	var debugInfo *DebugInfo
+	for i, detail := range b.details {
+		errDetailsDebugInfo := &errdetails.DebugInfo{}
+		if !detail.MessageIs(errDetailsDebugInfo) || detail.UnmarshalTo(errDetailsDebugInfo) != nil {
+			continue
+		}
+		if len(errDetailsDebugInfo.StackEntries) > 0 {
+			return b.details
+		}
+		index, debugInfo = i, &DebugInfo{Detail: errDetailsDebugInfo.Detail}
+		break
+	}
+
+	withStack := debugInfoWithStackSkip(debugInfo, true, skip+1)
+	if withStack == nil || len(withStack.StackEntries) == 0 {
+		return b.details
+	}
+	anyDetail, err := anypb.New(&errdetails.DebugInfo{
+		StackEntries: withStack.StackEntries,
+		Detail:       withStack.Detail,
+	})
+	if err != nil {
+		return b.details
+	}
+
+	details := append([]*anypb.Any(nil), b.details...)
+	if index < 0 {
+		return append(details, anyDetail)
+	}
+	details[index] = anyDetail
+
+	return details
+}
+
+// defaultErrMsg returns the default error message for the code. If there isn't any, an empty
+// string is returned.
+func defaultErrMsg(code codes.Code) string {
+	switch code {
+	case codes.InvalidArgument:
+		return defaultInvalidArgumentErrMsg
+	case codes.OutOfRange:
+		return defaultOutOfRangeErrMsg
+	case codes.FailedPrecondition:
+		return defaultFailedPreconditionErrMsg
+	case codes.Unauthenticated:
+		return defaultUnauthenticatedErrMsg
+	case codes.PermissionDenied:
+		return defaultPermissionDeniedErrMsg
+	case codes.Aborted:
+		return defaultAbortedErrMsg
+	case codes.NotFound:
+		return defaultNotFoundErrMsg
+	case codes.AlreadyExists:
+		return defaultAlreadyExistsErrMsg
+	case codes.ResourceExhausted:
+		return defaultResourceExhaustedErrMsg
+	case codes.Canceled:
+		return defaultCanceledErrMsg
+	case codes.DataLoss:
+		return defaultDataLossErrMsg
+	case codes.Unknown:
+		return defaultUnknownErrMsg
+	case codes.Internal:
+		return defaultInternalErrMsg
+	case codes.Unimplemented:
+		return defaultUnimplementedErrMsg
+	case codes.Unavailable:
+		return defaultUnavailableErrMsg
+	case codes.DeadlineExceeded:
+		return defaultDeadlineExceededErrMsg
+	}
+
+	return ""
+}
@@ -0,0 +1,127 @@
+package grpcerr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tobbstr/testa/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestBuilderErr(t *testing.T) {
+	notFoundWithDetails, err := status.New(codes.NotFound, "dummy-msg").WithDetails(
+		&errdetails.ResourceInfo{ResourceType: "dummy-resource-type", ResourceName: "dummy-resource-name"},
+		&errdetails.RequestInfo{RequestId: "dummy-request-id"},
+		&errdetails.ErrorInfo{Reason: "dummy-reason", Domain: "dummy-domain"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	internalWithAllDetails, err := status.New(codes.Internal, defaultInternalErrMsg).WithDetails(
+		&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "dummy-field", Description: "dummy-description"}}},
+		&errdetails.PreconditionFailure{Violations: []*errdetails.PreconditionFailure_Violation{{Type: "dummy-type", Subject: "dummy-subject", Description: "dummy-description"}}},
+		&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{Subject: "dummy-subject", Description: "dummy-description"}}},
+		&errdetails.DebugInfo{StackEntries: []string{"dummy-stack-entry"}, Detail: "dummy-detail"},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(time.Second)},
+		&errdetails.Help{Links: []*errdetails.Help_Link{{Description: "dummy-description", Url: "dummy-url"}}},
+		&errdetails.LocalizedMessage{Locale: "en-US", Message: "dummy-localized-msg"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		builder *Builder
+		want    *status.Status
+	}{
+		{
+			name:    "should return gRPC error with default message when no message is set",
+			builder: New(codes.Unavailable),
+			want:    status.New(codes.Unavailable, defaultUnavailableErrMsg),
+		},
+		{
+			name:    "should return gRPC error with message when message is set",
+			builder: New(codes.Unavailable).Message("dummy-msg"),
+			want:    status.New(codes.Unavailable, "dummy-msg"),
+		},
+		{
+			name: "should return gRPC error with details in the order they were added",
+			builder: New(codes.NotFound).
+				Message("dummy-msg").
+				ResourceInfo(&ResourceInfo{ResourceType: "dummy-resource-type", ResourceName: "dummy-resource-name"}).
+				RequestInfo(&RequestInfo{RequestID: "dummy-request-id"}).
+				ErrorInfo(&ErrorInfo{Reason: "dummy-reason", Domain: "dummy-domain"}),
+			want: notFoundWithDetails,
+		},
+		{
+			name: "should return gRPC error with any combination of details for any code",
+			builder: New(codes.Internal).
+				FieldViolations([]FieldViolation{{Field: "dummy-field", Description: "dummy-description"}}).
+				PreconditionFailures([]PreconditionFailure{{Type: "dummy-type", Subject: "dummy-subject", Description: "dummy-description"}}).
+				QuotaViolations([]QuotaViolation{{Subject: "dummy-subject", Description: "dummy-description"}}).
+				DebugInfo(&DebugInfo{StackEntries: []string{"dummy-stack-entry"}, Detail: "dummy-detail"}).
+				RetryInfo(&RetryInfo{RetryDelay: time.Second}).
+				Help([]HelpLink{{Description: "dummy-description", URL: "dummy-url"}}).
+				LocalizedMessage(&LocalizedMessage{Locale: "en-US", Message: "dummy-localized-msg"}),
+			want: internalWithAllDetails,
+		},
+		{
+			name: "should ignore nil and empty details",
+			builder: New(codes.Aborted).
+				ErrorInfo(nil).
+				FieldViolations(nil).
+				PreconditionFailures(nil).
+				QuotaViolations(nil).
+				ResourceInfo(nil).
+				RequestInfo(nil).
+				DebugInfo(nil).
+				RetryInfo(nil).
+				Help(nil).
+				LocalizedMessage(nil),
+			want: status.New(codes.Aborted, defaultAbortedErrMsg),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+
+			// When
+			got := tt.builder.Err()
+
+			// Then
+			assert(status.Convert(got).Proto()).Equals(tt.want.Proto())
+		})
+	}
+}
+
+func TestBuilderErrWithCodeOK(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+
+	// When
+	got := New(codes.OK).Err()
+
+	// Then
+	assert(got).IsNil()
+}
+
+func TestBuilderErrWhenDetailCannotBeMarshalled(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+	invalidUTF8 := string([]byte{0xff, 0xfe})
+
+	// When
+	got := New(codes.NotFound).
+		ErrorInfo(&ErrorInfo{Reason: invalidUTF8}).
+		RequestInfo(&RequestInfo{RequestID: "dummy-request-id"}).
+		Err()
+
+	// Then
+	assert(Code(got)).Equals(codes.Internal)
+	assert(RequestInfoFrom(got)).Equals(RequestInfo{})
+}
@@ -0,0 +1,116 @@
+package grpcerr
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	cpb "google.golang.org/genproto/googleapis/rpc/code"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// connectError is the JSON error of the Connect protocol.
+type connectError struct {
+	Code    string          `json:"code"`
+	Message string          `json:"message,omitempty"`
+	Details []connectDetail `json:"details,omitempty"`
+}
+
+// connectDetail is an error detail of the Connect protocol. The type is the fully-qualified name of the
+// protobuf message, and the value is the base64 encoded binary protobuf message.
+type connectDetail struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// AsConnectJSON encodes the gRPC error as a JSON error of the Connect protocol and writes it to the
+// http.ResponseWriter. For example:
+//
+//	{"code": "not_found", "message": "user not found", "details": [{"type": "google.rpc.ResourceInfo", "value": "..."}]}
+//
+// The default HTTP status codes are the ones of the Connect protocol.
+// If the error isn't a gRPC error, it's mapped to one using FromError.
+// If an error occurs it is returned, otherwise it returns nil.
+func (f *httpResponseEncoder) AsConnectJSON() error {
+	return f.Encode(connectJSONEncoder{})
+}
+
+// connectJSONEncoder is the Encoder used by AsConnectJSON.
+type connectJSONEncoder struct{}
+
+func (connectJSONEncoder) ContentType() string {
+	return contentTypeJSON
+}
+
+func (connectJSONEncoder) Encode(st *status.Status, _ int, _ http.Header) ([]byte, error) {
+	data, err := json.Marshal(connectErrorFrom(st))
+	if err != nil {
+		return nil, fmt.Errorf("could not get Connect error as bytes from gRPC status: %w", err)
+	}
+	return data, nil
+}
+
+func connectErrorFrom(st *status.Status) connectError {
+	connectErr := connectError{
+		Code:    connectCode(st.Code()),
+		Message: st.Message(),
+	}
+	for _, detail := range st.Proto().Details {
+		connectErr.Details = append(connectErr.Details, connectDetail{
+			Type:  string(detail.MessageName()),
+			Value: base64.RawStdEncoding.EncodeToString(detail.Value),
+		})
+	}
+
+	return connectErr
+}
+
+// connectCode returns the Connect code of the gRPC code, which is the lowercase name of the gRPC code,
+// except for Canceled which is spelled "canceled".
+func connectCode(code codes.Code) string {
+	if code == codes.Canceled {
+		return "canceled"
+	}
+	return strings.ToLower(cpb.Code(code).String())
+}
+
+// StatusFromConnectJSON returns the gRPC status held by a JSON error of the Connect protocol, which is the
+// inverse of encoding a gRPC error using AsConnectJSON. As required by the Connect protocol, unrecognized
+// codes are mapped to Unknown.
+func StatusFromConnectJSON(data []byte) (*status.Status, error) {
+	st, _, err := statusFromConnectJSON(data)
+	return st, err
+}
+
+// statusFromConnectJSON is like StatusFromConnectJSON, but it also returns whether the code was recognized.
+func statusFromConnectJSON(data []byte) (*status.Status, bool, error) {
+	var connectErr connectError
+	if err := json.Unmarshal(data, &connectErr); err != nil {
+		return nil, false, fmt.Errorf("could not unmarshal Connect error: %w", err)
+	}
+	if connectErr.Code == "" {
+		return nil, false, fmt.Errorf("invalid argument: Connect error has no code")
+	}
+
+	code, recognized := parseCode(connectErr.Code)
+	if !recognized || code == codes.OK {
+		code, recognized = codes.Unknown, false
+	}
+
+	st := &spb.Status{Code: int32(code), Message: connectErr.Message}
+	for _, detail := range connectErr.Details {
+		// Connect uses unpadded base64, but padded base64 is accepted too
+		value, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(detail.Value, "="))
+		if err != nil {
+			return nil, false, fmt.Errorf("could not decode value of Connect error detail %q: %w", detail.Type, err)
+		}
+		st.Details = append(st.Details, &anypb.Any{TypeUrl: "type.googleapis.com/" + detail.Type, Value: value})
+	}
+
+	return status.FromProto(st), recognized, nil
+}
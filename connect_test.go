@@ -0,0 +1,166 @@
+package grpcerr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tobbstr/testa/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestHttpResponseEncodeWriteAsConnectJSON(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+	w := httptest.NewRecorder()
+	gRPCErr := New(codes.InvalidArgument).
+		Message("dummy-msg").
+		RequestInfo(&RequestInfo{RequestID: "dummy-request-id"}).
+		Err()
+
+	// When
+	err := NewHttpResponseEncodeWriter(w)(gRPCErr).AsConnectJSON()
+
+	// Then
+	assert(err).IsNil()
+	assert(w.Code).Equals(http.StatusBadRequest)
+	assert(w.Header().Get("Content-Type")).Equals("application/json")
+	// The value is the unpadded base64 encoded RequestInfo{RequestId: "dummy-request-id"}
+	assert(w.Body.String()).IsJSONEqualTo(`{
+		"code": "invalid_argument",
+		"message": "dummy-msg",
+		"details": [{"type": "google.rpc.RequestInfo", "value": "ChBkdW1teS1yZXF1ZXN0LWlk"}]
+	}`)
+}
+
+func TestHttpResponseEncodeWriteAsConnectJSONCodes(t *testing.T) {
+	// The codes and HTTP status codes are the ones of the Connect protocol specification
+	tests := []struct {
+		code           codes.Code
+		wantCode       string
+		wantStatusCode int
+	}{
+		{code: codes.Canceled, wantCode: "canceled", wantStatusCode: 499},
+		{code: codes.Unknown, wantCode: "unknown", wantStatusCode: 500},
+		{code: codes.InvalidArgument, wantCode: "invalid_argument", wantStatusCode: 400},
+		{code: codes.DeadlineExceeded, wantCode: "deadline_exceeded", wantStatusCode: 504},
+		{code: codes.NotFound, wantCode: "not_found", wantStatusCode: 404},
+		{code: codes.AlreadyExists, wantCode: "already_exists", wantStatusCode: 409},
+		{code: codes.PermissionDenied, wantCode: "permission_denied", wantStatusCode: 403},
+		{code: codes.ResourceExhausted, wantCode: "resource_exhausted", wantStatusCode: 429},
+		{code: codes.FailedPrecondition, wantCode: "failed_precondition", wantStatusCode: 400},
+		{code: codes.Aborted, wantCode: "aborted", wantStatusCode: 409},
+		{code: codes.OutOfRange, wantCode: "out_of_range", wantStatusCode: 400},
+		{code: codes.Unimplemented, wantCode: "unimplemented", wantStatusCode: 501},
+		{code: codes.Internal, wantCode: "internal", wantStatusCode: 500},
+		{code: codes.Unavailable, wantCode: "unavailable", wantStatusCode: 503},
+		{code: codes.DataLoss, wantCode: "data_loss", wantStatusCode: 500},
+		{code: codes.Unauthenticated, wantCode: "unauthenticated", wantStatusCode: 401},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			w := httptest.NewRecorder()
+
+			// When
+			err := NewHttpResponseEncodeWriter(w)(New(tt.code).Message("dummy-msg").Err()).AsConnectJSON()
+
+			// Then
+			assert(err).IsNil()
+			assert(w.Code).Equals(tt.wantStatusCode)
+			assert(w.Body.String()).IsJSONEqualTo(`{"code": "` + tt.wantCode + `", "message": "dummy-msg"}`)
+			st, err := StatusFromConnectJSON(w.Body.Bytes())
+			assert(err).IsNil()
+			assert(st.Code()).Equals(tt.code)
+		})
+	}
+}
+
+func TestStatusFromConnectJSON(t *testing.T) {
+	tests := []struct {
+		name            string
+		data            string
+		wantErr         bool
+		wantCode        codes.Code
+		wantMsg         string
+		wantRequestInfo RequestInfo
+	}{
+		{
+			name:            "should return status with details when get unpadded base64 value",
+			data:            `{"code": "not_found", "message": "dummy-msg", "details": [{"type": "google.rpc.RequestInfo", "value": "ChBkdW1teS1yZXF1ZXN0LWlk"}]}`,
+			wantCode:        codes.NotFound,
+			wantMsg:         "dummy-msg",
+			wantRequestInfo: RequestInfo{RequestID: "dummy-request-id"},
+		},
+		{
+			name:            "should return status with details when get padded base64 value",
+			data:            `{"code": "not_found", "message": "dummy-msg", "details": [{"type": "google.rpc.RequestInfo", "value": "Cg5kdW1teS1yZXF1ZXN0cw=="}]}`,
+			wantCode:        codes.NotFound,
+			wantMsg:         "dummy-msg",
+			wantRequestInfo: RequestInfo{RequestID: "dummy-requests"},
+		},
+		{
+			name:     "should return Unknown status when get unrecognized code",
+			data:     `{"code": "dummy-code", "message": "dummy-msg"}`,
+			wantCode: codes.Unknown,
+			wantMsg:  "dummy-msg",
+		},
+		{
+			name:    "should return error when get JSON without code",
+			data:    `{"message": "dummy-msg"}`,
+			wantErr: true,
+		},
+		{
+			name:    "should return error when get invalid base64 value",
+			data:    `{"code": "not_found", "details": [{"type": "google.rpc.RequestInfo", "value": "!"}]}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+
+			// When
+			st, err := StatusFromConnectJSON([]byte(tt.data))
+
+			// Then
+			if tt.wantErr {
+				assert(err).IsNotNil()
+				return
+			}
+			assert(err).IsNil()
+			assert(st.Code()).Equals(tt.wantCode)
+			assert(st.Message()).Equals(tt.wantMsg)
+			assert(RequestInfoFrom(st.Err())).Equals(tt.wantRequestInfo)
+		})
+	}
+}
+
+func TestFromHTTPResponseConnectJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode codes.Code
+	}{
+		{name: "should return status when get Connect error", body: `{"code": "already_exists"}`, wantCode: codes.AlreadyExists},
+		{name: "should derive code from HTTP status code when get unrecognized code", body: `{"code": "dummy-code"}`, wantCode: codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			w := httptest.NewRecorder()
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.WriteString(tt.body)
+
+			// When
+			got := FromHTTPResponse(w.Result())
+
+			// Then
+			assert(Code(got)).Equals(tt.wantCode)
+		})
+	}
+}
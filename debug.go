@@ -0,0 +1,206 @@
+package grpcerr
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// ErrorRecord describes an error written by the HTTP response encoder or returned through the server
+// interceptors. It holds the error as it was before it was masked or redacted.
+type ErrorRecord struct {
+	Time time.Time `json:"time"`
+	// Where the error was sent, "http" for the HTTP response encoder or the full gRPC method name for
+	// the server interceptors.
+	Source       string   `json:"source"`
+	Code         string   `json:"code"`
+	Message      string   `json:"message"`
+	Reason       string   `json:"reason,omitempty"`
+	Domain       string   `json:"domain,omitempty"`
+	RequestID    string   `json:"requestId,omitempty"`
+	ServingData  string   `json:"servingData,omitempty"`
+	IncidentID   string   `json:"incidentId,omitempty"`
+	StackEntries []string `json:"stackEntries,omitempty"`
+}
+
+// RecentErrors keeps the most recent errors written by the HTTP response encoder or returned through the
+// server interceptors, once it's been set using SetRecentErrors. It's also an http.Handler which lists
+// them, meant to be mounted at a debug path, like the expvar and pprof handlers.
+//
+// The handler supports the following query parameters:
+//
+//	code=NotFound     only lists errors with the code, given by name or number
+//	request_id=abc123 only shows the error with the request ID or incident ID
+//	format=html       renders HTML instead of JSON, which is also the case if the Accept header prefers it
+type RecentErrors struct {
+	ring *ring
+}
+
+// NewRecentErrors returns a RecentErrors which keeps the given number of most recent errors.
+func NewRecentErrors(size int) *RecentErrors {
+	return &RecentErrors{ring: newRing(size)}
+}
+
+// currentRecentErrors holds the *RecentErrors set using SetRecentErrors.
+var currentRecentErrors atomic.Value
+
+// SetRecentErrors sets where the HTTP response encoder and the server interceptors record errors. If nil,
+// which is the default, errors aren't recorded.
+//
+// Example:
+//
+//	recentErrors := grpcerr.NewRecentErrors(100)
+//	grpcerr.SetRecentErrors(recentErrors)
+//	debugMux.Handle("/debug/grpcerr", recentErrors)
+func SetRecentErrors(recentErrors *RecentErrors) {
+	currentRecentErrors.Store(recentErrors)
+}
+
+// recordRecentError records gRPCErr, if recording is enabled. The masked error is only used to obtain the
+// incident ID, if gRPCErr was masked.
+func recordRecentError(source string, gRPCErr, masked error) {
+	recentErrors, _ := currentRecentErrors.Load().(*RecentErrors)
+	if recentErrors == nil || gRPCErr == nil {
+		return
+	}
+
+	recentErrors.Record(source, gRPCErr, masked)
+}
+
+// Record records gRPCErr. If gRPCErr was masked, the masked error is used to obtain the incident ID,
+// otherwise it must be gRPCErr or nil.
+func (r *RecentErrors) Record(source string, gRPCErr, masked error) {
+	errorInfo := ErrorInfoFrom(gRPCErr)
+	requestInfo := RequestInfoFrom(gRPCErr)
+	record := ErrorRecord{
+		Time:         time.Now(),
+		Source:       source,
+		Code:         Code(gRPCErr).String(),
+		Message:      Message(gRPCErr),
+		Reason:       errorInfo.Reason,
+		Domain:       errorInfo.Domain,
+		RequestID:    requestInfo.RequestID,
+		ServingData:  requestInfo.ServingData,
+		StackEntries: DebugInfoFrom(gRPCErr).StackEntries,
+	}
+	if masked != nil && masked != gRPCErr {
+		record.IncidentID = RequestInfoFrom(masked).RequestID
+	}
+
+	r.ring.add(record)
+}
+
+// Records returns the recorded errors, the most recent first.
+func (r *RecentErrors) Records() []ErrorRecord {
+	items := r.ring.items()
+	records := make([]ErrorRecord, 0, len(items))
+	for _, item := range items {
+		records = append(records, item.(ErrorRecord))
+	}
+
+	return records
+}
+
+// ServeHTTP lists the recorded errors as JSON or HTML.
+func (r *RecentErrors) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	asHTML := query.Get("format") == "html" ||
+		(query.Get("format") == "" && strings.Contains(req.Header.Get("Accept"), "text/html"))
+
+	records := r.Records()
+
+	if code := query.Get("code"); code != "" {
+		c, ok := parseCode(code)
+		if !ok {
+			http.Error(w, "invalid code: "+code, http.StatusBadRequest)
+			return
+		}
+		records = filterRecords(records, func(record ErrorRecord) bool { return record.Code == c.String() })
+	}
+
+	if requestID := query.Get("request_id"); requestID != "" {
+		records = filterRecords(records, func(record ErrorRecord) bool {
+			return record.RequestID == requestID || record.IncidentID == requestID
+		})
+		if len(records) == 0 {
+			http.Error(w, "no error found with request ID: "+requestID, http.StatusNotFound)
+			return
+		}
+		records = records[:1]
+	}
+
+	if asHTML {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		recentErrorsTemplate.Execute(w, records)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Errors []ErrorRecord `json:"errors"`
+	}{records})
+}
+
+func filterRecords(records []ErrorRecord, keep func(record ErrorRecord) bool) []ErrorRecord {
+	filtered := make([]ErrorRecord, 0, len(records))
+	for _, record := range records {
+		if keep(record) {
+			filtered = append(filtered, record)
+		}
+	}
+	return filtered
+}
+
+// parseCode parses a code given by its number or its name, ignoring case and underscores. For example,
+// "5", "NotFound" and "NOT_FOUND" all return codes.NotFound.
+func parseCode(s string) (codes.Code, bool) {
+	if n, err := strconv.ParseUint(s, 10, 32); err == nil {
+		if n > uint64(codes.Unauthenticated) {
+			return 0, false
+		}
+		return codes.Code(n), true
+	}
+
+	name := strings.ReplaceAll(s, "_", "")
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		if strings.EqualFold(c.String(), name) {
+			return c, true
+		}
+	}
+	// The code's String() is "Canceled" while the google.rpc.Code name is "CANCELLED"
+	if strings.EqualFold(name, "CANCELLED") {
+		return codes.Canceled, true
+	}
+
+	return 0, false
+}
+
+var recentErrorsTemplate = template.Must(template.New("recentErrors").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Recent errors</title></head>
+<body>
+<h1>Recent errors</h1>
+<table border="1" cellpadding="4">
+<tr><th>Time</th><th>Source</th><th>Code</th><th>Message</th><th>Reason</th><th>Domain</th><th>Request ID</th><th>Incident ID</th><th>Stack</th></tr>
+{{range .}}<tr>
+<td>{{.Time.Format "2006-01-02T15:04:05.000Z07:00"}}</td>
+<td>{{.Source}}</td>
+<td><a href="?format=html&amp;code={{.Code}}">{{.Code}}</a></td>
+<td>{{.Message}}</td>
+<td>{{.Reason}}</td>
+<td>{{.Domain}}</td>
+<td>{{if .RequestID}}<a href="?format=html&amp;request_id={{.RequestID}}">{{.RequestID}}</a>{{end}}</td>
+<td>{{if .IncidentID}}<a href="?format=html&amp;request_id={{.IncidentID}}">{{.IncidentID}}</a>{{end}}</td>
+<td><pre>{{range .StackEntries}}{{.}}
+{{end}}</pre></td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
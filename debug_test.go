@@ -0,0 +1,169 @@
+package grpcerr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tobbstr/testa/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func TestRecentErrorsServeHTTP(t *testing.T) {
+	recentErrors := NewRecentErrors(10)
+	recentErrors.Record("http", New(codes.NotFound).
+		Message("dummy-not-found").
+		ErrorInfo(&ErrorInfo{Reason: "dummy-reason", Domain: "dummy-domain"}).
+		RequestInfo(&RequestInfo{RequestID: "dummy-request-id-1"}).
+		Err(), nil)
+	recentErrors.Record("/dummy.Service/DummyMethod", New(codes.Internal).
+		Message("dummy-internal").
+		RequestInfo(&RequestInfo{RequestID: "dummy-request-id-2"}).
+		DebugInfo(&DebugInfo{StackEntries: []string{"dummy-stack-entry"}}).
+		Err(), nil)
+
+	tests := []struct {
+		name           string
+		target         string
+		wantStatusCode int
+		wantMessages   []string
+	}{
+		{
+			name:           "should list all errors most recent first",
+			target:         "/debug/grpcerr",
+			wantStatusCode: http.StatusOK,
+			wantMessages:   []string{"dummy-internal", "dummy-not-found"},
+		},
+		{
+			name:           "should list errors with code when get code name",
+			target:         "/debug/grpcerr?code=NotFound",
+			wantStatusCode: http.StatusOK,
+			wantMessages:   []string{"dummy-not-found"},
+		},
+		{
+			name:           "should list errors with code when get code enum name",
+			target:         "/debug/grpcerr?code=NOT_FOUND",
+			wantStatusCode: http.StatusOK,
+			wantMessages:   []string{"dummy-not-found"},
+		},
+		{
+			name:           "should list errors with code when get code number",
+			target:         "/debug/grpcerr?code=13",
+			wantStatusCode: http.StatusOK,
+			wantMessages:   []string{"dummy-internal"},
+		},
+		{
+			name:           "should show error with request ID",
+			target:         "/debug/grpcerr?request_id=dummy-request-id-1",
+			wantStatusCode: http.StatusOK,
+			wantMessages:   []string{"dummy-not-found"},
+		},
+		{
+			name:           "should respond with not found when get unknown request ID",
+			target:         "/debug/grpcerr?request_id=dummy-unknown",
+			wantStatusCode: http.StatusNotFound,
+			wantMessages:   nil,
+		},
+		{
+			name:           "should respond with bad request when get invalid code",
+			target:         "/debug/grpcerr?code=dummy-code",
+			wantStatusCode: http.StatusBadRequest,
+			wantMessages:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+
+			// When
+			recentErrors.ServeHTTP(w, req)
+
+			// Then
+			assert(w.Code).Equals(tt.wantStatusCode)
+			if tt.wantMessages == nil {
+				return
+			}
+			assert(w.Header().Get("Content-Type")).Equals("application/json")
+			var body struct {
+				Errors []ErrorRecord `json:"errors"`
+			}
+			assert(json.Unmarshal(w.Body.Bytes(), &body)).IsNil()
+			gotMessages := []string{}
+			for _, record := range body.Errors {
+				gotMessages = append(gotMessages, record.Message)
+			}
+			assert(gotMessages).Equals(tt.wantMessages)
+		})
+	}
+}
+
+func TestRecentErrorsServeHTTPAsHTML(t *testing.T) {
+	recentErrors := NewRecentErrors(10)
+	recentErrors.Record("http", New(codes.Internal).
+		Message("<script>dummy-msg</script>").
+		DebugInfo(&DebugInfo{StackEntries: []string{"dummy-stack-entry"}}).
+		Err(), nil)
+
+	tests := []struct {
+		name   string
+		target string
+		accept string
+	}{
+		{name: "should render HTML when get html format", target: "/debug/grpcerr?format=html", accept: ""},
+		{name: "should render HTML when Accept header prefers it", target: "/debug/grpcerr", accept: "text/html,application/xhtml+xml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Header.Set("Accept", tt.accept)
+
+			// When
+			recentErrors.ServeHTTP(w, req)
+
+			// Then
+			assert(w.Code).Equals(http.StatusOK)
+			assert(w.Header().Get("Content-Type")).Equals("text/html; charset=utf-8")
+			assert(strings.Contains(w.Body.String(), "dummy-stack-entry")).IsTrue()
+			assert(strings.Contains(w.Body.String(), "&lt;script&gt;dummy-msg&lt;/script&gt;")).IsTrue()
+		})
+	}
+}
+
+func TestRecentErrorsRecordsFromEncoderAndInterceptor(t *testing.T) {
+	defer SetRecentErrors(nil)
+
+	// Given
+	assert := assert.New(t)
+	recentErrors := NewRecentErrors(10)
+	SetRecentErrors(recentErrors)
+	masking := &Masking{NewIncidentID: func() string { return "dummy-incident-id" }}
+	interceptor := UnaryServerInterceptor(WithMasking(masking))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, New(codes.Internal).Message("dummy-internal").Err()
+	}
+
+	// When
+	NewHttpResponseEncodeWriter(httptest.NewRecorder())(New(codes.NotFound).Message("dummy-not-found").Err()).AsJSON()
+	interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/dummy.Service/DummyMethod"}, handler)
+
+	// Then
+	records := recentErrors.Records()
+	assert(len(records)).Equals(2)
+	assert(records[0].Source).Equals("/dummy.Service/DummyMethod")
+	assert(records[0].Code).Equals("Internal")
+	assert(records[0].Message).Equals("dummy-internal")
+	assert(records[0].IncidentID).Equals("dummy-incident-id")
+	assert(records[1].Source).Equals("http")
+	assert(records[1].Code).Equals("NotFound")
+	assert(records[1].IncidentID).Equals("")
+}
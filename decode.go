@@ -0,0 +1,210 @@
+package grpcerr
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxErrorBodySize is the maximum number of bytes read from the body of an HTTP error response.
+const maxErrorBodySize = 1 << 20
+
+// FromHTTPResponse returns the gRPC error held by the HTTP response, which is the inverse of
+// encoding a gRPC error using the HTTP response encoder. If the response is successful, nil is returned.
+//
+// The body is expected to be a google.rpc.Status, including its details, encoded as JSON or as binary
+// protobuf if the Content-Type is application/x-protobuf. The JSON error envelope of Google APIs and the
+// JSON errors of the Connect and Twirp protocols are also accepted, see StatusFromGoogleAPIError,
+// StatusFromConnectJSON and StatusFromTwirpJSON. If the Content-Type is application/problem+json, it's decoded
+// using StatusFromProblemJSON. If the body doesn't hold a gRPC status, the gRPC error is taken from the
+// headers, see StatusFromHeaders, or else derived from the HTTP status code. The body is read but not closed,
+// which remains the caller's responsibility.
+//
+// Example:
+//
+//	resp, err := http.Get(url)
+//	if err != nil {
+//		return err
+//	}
+//	defer resp.Body.Close()
+//	if err := grpcerr.FromHTTPResponse(resp); err != nil {
+//		switch grpcerr.Code(err) {
+//		case codes.InvalidArgument:
+//			fieldViolations := grpcerr.FieldViolationsFrom(err)
+//			...
+//		}
+//	}
+func FromHTTPResponse(resp *http.Response) error {
+	if resp == nil {
+		return fmt.Errorf("invalid argument: resp was nil")
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	var body []byte
+	if resp.Body != nil {
+		body, _ = io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	}
+
+	if st, ok := statusFromBody(resp.Header.Get("Content-Type"), body); ok {
+		return &Error{st: st}
+	}
+	if st, err := StatusFromHeaders(resp.Header); err == nil {
+		return &Error{st: st}
+	}
+
+	return New(CodeFromHTTPStatus(resp.StatusCode)).Message(resp.Status).errWithoutStack()
+}
+
+// NewFromUpstreamResponse constructs a gRPC error from the HTTP response of an upstream service, such as a
+// third-party REST API, which doesn't encode its errors as gRPC statuses. The code is derived from the HTTP
+// status code using CodeFromHTTPStatus, and an excerpt of the body is added as a DebugInfo detail.
+// If the response is successful, nil is returned and the body is left unread.
+//
+// Like FromHTTPResponse, the body of an unsuccessful response is read but not closed.
+func NewFromUpstreamResponse(resp *http.Response) error {
+	if resp == nil {
+		return fmt.Errorf("invalid argument: resp was nil")
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	var excerpt []byte
+	if resp.Body != nil {
+		excerpt, _ = io.ReadAll(io.LimitReader(resp.Body, maxBodyExcerptSize+1))
+	}
+
+	return New(CodeFromHTTPStatus(resp.StatusCode)).
+		Message("upstream responded with " + resp.Status).
+		DebugInfo(&DebugInfo{Detail: bodyExcerpt(excerpt)}).
+		errWithoutStack()
+}
+
+// maxBodyExcerptSize is the maximum number of bytes of an upstream response body kept in the DebugInfo.
+const maxBodyExcerptSize = 1 << 10
+
+// bodyExcerpt returns the body as a string, truncated to maxBodyExcerptSize bytes.
+func bodyExcerpt(body []byte) string {
+	if len(body) <= maxBodyExcerptSize {
+		return strings.ToValidUTF8(string(body), "")
+	}
+	return strings.ToValidUTF8(string(body[:maxBodyExcerptSize]), "") + "..."
+}
+
+// statusFromBody returns the status held by the body, decoded according to the content type. The boolean
+// is false if the body doesn't hold a status.
+func statusFromBody(contentType string, body []byte) (*status.Status, bool) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case contentTypeProtobuf:
+		return statusFrom(body, proto.Unmarshal)
+	case contentTypeProblemJSON:
+		st, err := StatusFromProblemJSON(body)
+		return st, err == nil
+	default:
+		if st, ok := statusFrom(body, protojson.Unmarshal); ok {
+			return st, true
+		}
+		if st, err := StatusFromGoogleAPIError(body); err == nil {
+			return st, true
+		}
+		// Connect and Twirp errors are only accepted if their code is recognized, since a code alone is
+		// too common
+		decode := statusFromConnectJSON
+		if isTwirpJSON(body) {
+			decode = statusFromTwirpJSON
+		}
+		st, recognized, err := decode(body)
+		return st, err == nil && recognized
+	}
+}
+
+// statusFrom returns the status held by the encoded google.rpc.Status. The boolean is false if the data
+// isn't a google.rpc.Status, or if its code isn't a gRPC error code. For example, the body
+// {"code": 404, "message": "Not Found"} of many REST APIs isn't a gRPC status.
+func statusFrom(data []byte, unmarshal func(data []byte, m proto.Message) error) (*status.Status, bool) {
+	if len(data) == 0 {
+		return nil, false
+	}
+
+	var st spb.Status
+	if err := unmarshal(data, &st); err != nil {
+		return nil, false
+	}
+	if !isErrorCode(codes.Code(st.Code)) {
+		return nil, false
+	}
+
+	return status.FromProto(&st), true
+}
+
+// isErrorCode reports whether the code is one of the gRPC codes other than OK.
+func isErrorCode(code codes.Code) bool {
+	return code > codes.OK && code <= codes.Unauthenticated
+}
+
+// CodeFromHTTPStatus returns the gRPC code corresponding to the HTTP status code, which is the inverse of
+// the mapping used when encoding gRPC errors. It follows the mapping documented for google.rpc.Code:
+//
+//	2xx -> OK
+//	400 -> InvalidArgument
+//	401 -> Unauthenticated
+//	403 -> PermissionDenied
+//	404 -> NotFound
+//	409 -> Aborted
+//	416 -> OutOfRange
+//	429 -> ResourceExhausted
+//	499 -> Canceled
+//	501 -> Unimplemented
+//	503 -> Unavailable
+//	504 -> DeadlineExceeded
+//
+// Other 4xx status codes are mapped to FailedPrecondition, other 5xx status codes to Internal and
+// everything else to Unknown.
+func CodeFromHTTPStatus(httpStatusCode int) codes.Code {
+	switch httpStatusCode {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusRequestedRangeNotSatisfiable:
+		return codes.OutOfRange
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case 499:
+		return codes.Canceled
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+
+	switch {
+	case httpStatusCode >= 200 && httpStatusCode < 300:
+		return codes.OK
+	case httpStatusCode >= 400 && httpStatusCode < 500:
+		return codes.FailedPrecondition
+	case httpStatusCode >= 500 && httpStatusCode < 600:
+		return codes.Internal
+	}
+
+	return codes.Unknown
+}
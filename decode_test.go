@@ -0,0 +1,200 @@
+package grpcerr
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tobbstr/testa/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestFromHTTPResponse(t *testing.T) {
+	errorInfo := ErrorInfo{Reason: "dummy-reason", Domain: "dummy-domain", Metadata: map[string]string{"dummy-key": "dummy-value"}}
+	fieldViolations := []FieldViolation{{Field: "dummy-field", Description: "dummy-desc"}}
+	invalidArgument := New(codes.InvalidArgument).
+		Message("dummy-msg").
+		ErrorInfo(&errorInfo).
+		FieldViolations(fieldViolations).
+		Err()
+
+	tests := []struct {
+		name                string
+		resp                *http.Response
+		wantNil             bool
+		wantCode            codes.Code
+		wantMsg             string
+		wantErrorInfo       ErrorInfo
+		wantFieldViolations []FieldViolation
+	}{
+		{
+			name:    "should return nil when get successful response",
+			resp:    &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: io.NopCloser(strings.NewReader("{}"))},
+			wantNil: true,
+		},
+		{
+			name:                "should return gRPC error with details when get response encoded using AsJSON",
+			resp:                recordedResponse(t, invalidArgument),
+			wantCode:            codes.InvalidArgument,
+			wantMsg:             "dummy-msg",
+			wantErrorInfo:       errorInfo,
+			wantFieldViolations: fieldViolations,
+		},
+		{
+			name:                "should derive gRPC error from HTTP status code when get non-status JSON body",
+			resp:                &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found", Body: io.NopCloser(strings.NewReader(`{"error":"dummy-error"}`))},
+			wantCode:            codes.NotFound,
+			wantMsg:             "404 Not Found",
+			wantFieldViolations: []FieldViolation{},
+		},
+		{
+			name:                "should derive gRPC error from HTTP status code when get plain text body",
+			resp:                &http.Response{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable", Body: io.NopCloser(strings.NewReader("dummy-body"))},
+			wantCode:            codes.Unavailable,
+			wantMsg:             "503 Service Unavailable",
+			wantFieldViolations: []FieldViolation{},
+		},
+		{
+			name:                "should derive gRPC error from HTTP status code when get nil body",
+			resp:                &http.Response{StatusCode: http.StatusTeapot, Status: "418 I'm a teapot"},
+			wantCode:            codes.FailedPrecondition,
+			wantMsg:             "418 I'm a teapot",
+			wantFieldViolations: []FieldViolation{},
+		},
+		{
+			name:                "should derive gRPC error from HTTP status code when get JSON body with OK code",
+			resp:                &http.Response{StatusCode: http.StatusBadGateway, Status: "502 Bad Gateway", Body: io.NopCloser(strings.NewReader(`{"code":0}`))},
+			wantCode:            codes.Internal,
+			wantMsg:             "502 Bad Gateway",
+			wantFieldViolations: []FieldViolation{},
+		},
+		{
+			name:                "should derive gRPC error from HTTP status code when get JSON body with HTTP status code",
+			resp:                &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found", Body: io.NopCloser(strings.NewReader(`{"code":404,"message":"Not Found"}`))},
+			wantCode:            codes.NotFound,
+			wantMsg:             "404 Not Found",
+			wantFieldViolations: []FieldViolation{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+
+			// When
+			got := FromHTTPResponse(tt.resp)
+
+			// Then
+			if tt.wantNil {
+				assert(got).IsNil()
+				return
+			}
+			assert(Code(got)).Equals(tt.wantCode)
+			assert(Message(got)).Equals(tt.wantMsg)
+			assert(ErrorInfoFrom(got)).Equals(tt.wantErrorInfo)
+			assert(FieldViolationsFrom(got)).Equals(tt.wantFieldViolations)
+		})
+	}
+}
+
+func TestCodeFromHTTPStatus(t *testing.T) {
+	tests := []struct {
+		httpStatusCode int
+		want           codes.Code
+	}{
+		{httpStatusCode: http.StatusOK, want: codes.OK},
+		{httpStatusCode: http.StatusNoContent, want: codes.OK},
+		{httpStatusCode: http.StatusBadRequest, want: codes.InvalidArgument},
+		{httpStatusCode: http.StatusUnauthorized, want: codes.Unauthenticated},
+		{httpStatusCode: http.StatusForbidden, want: codes.PermissionDenied},
+		{httpStatusCode: http.StatusNotFound, want: codes.NotFound},
+		{httpStatusCode: http.StatusConflict, want: codes.Aborted},
+		{httpStatusCode: http.StatusRequestedRangeNotSatisfiable, want: codes.OutOfRange},
+		{httpStatusCode: http.StatusTooManyRequests, want: codes.ResourceExhausted},
+		{httpStatusCode: 499, want: codes.Canceled},
+		{httpStatusCode: http.StatusTeapot, want: codes.FailedPrecondition},
+		{httpStatusCode: http.StatusInternalServerError, want: codes.Internal},
+		{httpStatusCode: http.StatusNotImplemented, want: codes.Unimplemented},
+		{httpStatusCode: http.StatusBadGateway, want: codes.Internal},
+		{httpStatusCode: http.StatusServiceUnavailable, want: codes.Unavailable},
+		{httpStatusCode: http.StatusGatewayTimeout, want: codes.DeadlineExceeded},
+		{httpStatusCode: http.StatusFound, want: codes.Unknown},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.httpStatusCode), func(t *testing.T) {
+			assert.New(t)(CodeFromHTTPStatus(tt.httpStatusCode)).Equals(tt.want)
+		})
+	}
+}
+
+// recordedResponse returns the HTTP response written by AsJSON when encoding gRPCErr.
+func recordedResponse(t *testing.T, gRPCErr error) *http.Response {
+	w := httptest.NewRecorder()
+	if err := NewHttpResponseEncodeWriter(w)(gRPCErr).AsJSON(); err != nil {
+		t.Fatal(err)
+	}
+	return w.Result()
+}
+
+func TestNewFromUpstreamResponse(t *testing.T) {
+	longBody := strings.Repeat("a", maxBodyExcerptSize+10)
+
+	tests := []struct {
+		name          string
+		resp          *http.Response
+		wantNil       bool
+		wantCode      codes.Code
+		wantMsg       string
+		wantDebugInfo DebugInfo
+	}{
+		{
+			name:    "should return nil and leave body unread when get successful response",
+			resp:    &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: io.NopCloser(strings.NewReader("dummy-body"))},
+			wantNil: true,
+		},
+		{
+			name:          "should keep body in DebugInfo when get short body",
+			resp:          &http.Response{StatusCode: http.StatusConflict, Status: "409 Conflict", Body: io.NopCloser(strings.NewReader(`{"error":"dummy-error"}`))},
+			wantCode:      codes.Aborted,
+			wantMsg:       "upstream responded with 409 Conflict",
+			wantDebugInfo: DebugInfo{Detail: `{"error":"dummy-error"}`},
+		},
+		{
+			name:          "should keep body excerpt in DebugInfo when get long body",
+			resp:          &http.Response{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests", Body: io.NopCloser(strings.NewReader(longBody))},
+			wantCode:      codes.ResourceExhausted,
+			wantMsg:       "upstream responded with 429 Too Many Requests",
+			wantDebugInfo: DebugInfo{Detail: longBody[:maxBodyExcerptSize] + "..."},
+		},
+		{
+			name:          "should return error without DebugInfo detail when get nil body",
+			resp:          &http.Response{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable"},
+			wantCode:      codes.Unavailable,
+			wantMsg:       "upstream responded with 503 Service Unavailable",
+			wantDebugInfo: DebugInfo{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+
+			// When
+			got := NewFromUpstreamResponse(tt.resp)
+
+			// Then
+			if tt.wantNil {
+				assert(got).IsNil()
+				body, err := io.ReadAll(tt.resp.Body)
+				assert(err).IsNil()
+				assert(string(body)).Equals("dummy-body")
+				return
+			}
+			assert(Code(got)).Equals(tt.wantCode)
+			assert(Message(got)).Equals(tt.wantMsg)
+			assert(DebugInfoFrom(got)).Equals(tt.wantDebugInfo)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package grpcerr
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	contentTypeJSON     = "application/json"
+	contentTypeProtobuf = "application/x-protobuf"
+)
+
+// Encoder encodes gRPC statuses into HTTP response bodies. It allows adding response formats to the
+// encoder returned by NewHttpResponseEncodeWriter, either by registering them using RegisterEncoder or by
+// passing them to Encode().
+type Encoder interface {
+	// ContentType returns the value of the Content-Type header of the responses.
+	ContentType() string
+	// Encode encodes the status, which is sent with the HTTP status code, into the response body. Headers
+	// added to header are sent with the response.
+	Encode(st *status.Status, httpStatusCode int, header http.Header) ([]byte, error)
+}
+
+// defaultHTTPStatusCoder is implemented by encoders of protocols whose default HTTP status codes differ
+// from the ones of this package.
+type defaultHTTPStatusCoder interface {
+	defaultHTTPStatusCode(st *status.Status) int
+}
+
+var encoders = struct {
+	sync.RWMutex
+	byMediaType map[string]Encoder
+	// mediaTypes holds the registered media types in the order they were registered, which is the order
+	// of preference when the Accept header doesn't prefer any of them.
+	mediaTypes []string
+}{
+	byMediaType: map[string]Encoder{
+		contentTypeJSON:        jsonEncoder{},
+		contentTypeProtobuf:    protobufEncoder{},
+		contentTypeProblemJSON: problemJSONEncoder{},
+		"text/html":            htmlEncoder{},
+		"text/plain":           textEncoder{},
+	},
+	mediaTypes: []string{contentTypeJSON, contentTypeProtobuf, contentTypeProblemJSON, "text/html", "text/plain"},
+}
+
+// RegisterEncoder registers the encoder for the media type, for example "application/vnd.api+json", which
+// makes it available to As() and Auto(). Registering an encoder for an already registered media type
+// replaces it. The media types registered by default are application/json, application/x-protobuf,
+// application/problem+json, text/html and text/plain.
+//
+// RegisterEncoder is meant to be called during initialization, for example in an init function.
+func RegisterEncoder(mediaType string, encoder Encoder) {
+	if encoder == nil {
+		return
+	}
+
+	encoders.Lock()
+	defer encoders.Unlock()
+
+	if _, ok := encoders.byMediaType[mediaType]; !ok {
+		encoders.mediaTypes = append(encoders.mediaTypes, mediaType)
+	}
+	encoders.byMediaType[mediaType] = encoder
+}
+
+func registeredEncoder(mediaType string) (Encoder, bool) {
+	encoders.RLock()
+	defer encoders.RUnlock()
+
+	encoder, ok := encoders.byMediaType[mediaType]
+	return encoder, ok
+}
+
+func registeredMediaTypes() []string {
+	encoders.RLock()
+	defer encoders.RUnlock()
+
+	return append([]string(nil), encoders.mediaTypes...)
+}
+
+// jsonEncoder is the Encoder used by AsJSON. Nil options mean the zero value JSONOptions.
+type jsonEncoder struct {
+	opts *JSONOptions
+}
+
+func (jsonEncoder) ContentType() string {
+	return contentTypeJSON
+}
+
+func (e jsonEncoder) Encode(st *status.Status, _ int, _ http.Header) ([]byte, error) {
+	data, err := jsonBytesFromGrpcStatus(st, e.opts.marshalOptions())
+	if err != nil {
+		return nil, fmt.Errorf("could not get JSON as bytes from gRPC status: %w", err)
+	}
+	return data, nil
+}
+
+// protobufEncoder is the Encoder used by AsProtobuf.
+type protobufEncoder struct{}
+
+func (protobufEncoder) ContentType() string {
+	return contentTypeProtobuf
+}
+
+func (protobufEncoder) Encode(st *status.Status, _ int, _ http.Header) ([]byte, error) {
+	data, err := proto.Marshal(st.Proto())
+	if err != nil {
+		return nil, fmt.Errorf("could not get protobuf as bytes from gRPC status: %w", err)
+	}
+	return data, nil
+}
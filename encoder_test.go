@@ -0,0 +1,165 @@
+package grpcerr
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tobbstr/testa/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type dummyEncoder struct {
+	err error
+}
+
+func (dummyEncoder) ContentType() string {
+	return "application/vnd.dummy+json"
+}
+
+func (e dummyEncoder) Encode(st *status.Status, httpStatusCode int, header http.Header) ([]byte, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	header.Set("X-Dummy", "dummy-header-value")
+	return []byte(fmt.Sprintf(`{"dummyCode": %q, "dummyStatus": %d}`, st.Code(), httpStatusCode)), nil
+}
+
+// registerDummyEncoder registers the dummy encoder and restores the registered encoders when the test ends.
+func registerDummyEncoder(t *testing.T, encoder Encoder) {
+	encoders.Lock()
+	byMediaType := make(map[string]Encoder, len(encoders.byMediaType))
+	for mediaType, encoder := range encoders.byMediaType {
+		byMediaType[mediaType] = encoder
+	}
+	mediaTypes := append([]string(nil), encoders.mediaTypes...)
+	encoders.Unlock()
+	t.Cleanup(func() {
+		encoders.Lock()
+		encoders.byMediaType, encoders.mediaTypes = byMediaType, mediaTypes
+		encoders.Unlock()
+	})
+
+	RegisterEncoder("application/vnd.dummy+json", encoder)
+}
+
+func TestHttpResponseEncodeWriteEncode(t *testing.T) {
+	tests := []struct {
+		name            string
+		encoder         Encoder
+		wantErr         bool
+		wantStatusCode  int
+		wantContentType string
+		wantHeader      string
+		wantBody        string
+	}{
+		{
+			name:            "Should write body and headers of encoder",
+			encoder:         dummyEncoder{},
+			wantStatusCode:  http.StatusNotFound,
+			wantContentType: "application/vnd.dummy+json",
+			wantHeader:      "dummy-header-value",
+			wantBody:        `{"dummyCode": "NotFound", "dummyStatus": 404}`,
+		},
+		{
+			name:           "Should write internal server error when encoder fails",
+			encoder:        dummyEncoder{err: fmt.Errorf("dummy-error")},
+			wantErr:        true,
+			wantStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			w := httptest.NewRecorder()
+
+			// When
+			err := NewHttpResponseEncodeWriter(w)(New(codes.NotFound).Err()).Encode(tt.encoder)
+
+			// Then
+			assert(err != nil).Equals(tt.wantErr)
+			assert(w.Code).Equals(tt.wantStatusCode)
+			assert(w.Header().Get("Content-Type")).Equals(tt.wantContentType)
+			assert(w.Header().Get("X-Dummy")).Equals(tt.wantHeader)
+			if tt.wantBody != "" {
+				assert(w.Body.String()).IsJSONEqualTo(tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestHttpResponseEncodeWriteAs(t *testing.T) {
+	tests := []struct {
+		name            string
+		mediaType       string
+		wantErr         bool
+		wantStatusCode  int
+		wantContentType string
+	}{
+		{
+			name:            "Should encode using registered encoder",
+			mediaType:       "application/vnd.dummy+json",
+			wantStatusCode:  http.StatusNotFound,
+			wantContentType: "application/vnd.dummy+json",
+		},
+		{
+			name:            "Should encode using encoder registered by default",
+			mediaType:       "application/problem+json",
+			wantStatusCode:  http.StatusNotFound,
+			wantContentType: "application/problem+json",
+		},
+		{
+			name:           "Should return error when no encoder is registered for media type",
+			mediaType:      "application/vnd.unknown+json",
+			wantErr:        true,
+			wantStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			registerDummyEncoder(t, dummyEncoder{})
+			w := httptest.NewRecorder()
+
+			// When
+			err := NewHttpResponseEncodeWriter(w)(New(codes.NotFound).Err()).As(tt.mediaType)
+
+			// Then
+			assert(err != nil).Equals(tt.wantErr)
+			assert(w.Code).Equals(tt.wantStatusCode)
+			assert(w.Header().Get("Content-Type")).Equals(tt.wantContentType)
+		})
+	}
+}
+
+func TestHttpResponseEncodeWriteAutoRegisteredEncoder(t *testing.T) {
+	tests := []struct {
+		name            string
+		accept          string
+		wantContentType string
+	}{
+		{name: "Should encode using registered encoder when it's preferred", accept: "application/json;q=0.5, application/vnd.dummy+json", wantContentType: "application/vnd.dummy+json"},
+		{name: "Should prefer encoders registered by default when get any media type", accept: "*/*", wantContentType: "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			registerDummyEncoder(t, dummyEncoder{})
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept", tt.accept)
+
+			// When
+			err := NewHttpResponseEncodeWriter(w)(New(codes.NotFound).Err()).Auto(r)
+
+			// Then
+			assert(err).IsNil()
+			assert(w.Header().Get("Content-Type")).Equals(tt.wantContentType)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package grpcerr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Error is a gRPC error which, in addition to the gRPC status, holds the Go error that caused it.
+// It implements the GRPCStatus() method, so it can be returned as is from gRPC handlers, and it
+// supports errors.Is, errors.As and errors.Unwrap.
+//
+// The cause is never sent to clients, only the gRPC status is. Use the %+v verb to print the
+// gRPC status including its details and the cause, for example when logging.
+type Error struct {
+	st    *status.Status
+	cause error
+}
+
+// Errorf returns an *Error with the code and a message formatted according to a format specifier.
+// If the format specifier includes a %w verb with an error operand, the operand becomes the cause
+// of the returned error. If it includes several %w verbs, which requires Go 1.20, the error formatted by
+// fmt.Errorf becomes the cause, which wraps all the operands. The stack is captured like Builder.Err does.
+func Errorf(code codes.Code, format string, a ...interface{}) error {
+	err := fmt.Errorf(format, a...)
+
+	cause := errors.Unwrap(err)
+	if _, ok := err.(interface{ Unwrap() []error }); ok {
+		cause = err
+	}
+
+	b := New(code).Message(err.Error()).Cause(cause)
+	return b.errWith(b.detailsWithStack(0))
+}
+
+// errorWithStatus returns an *Error with the status, which keeps the cause of gRPCErr. The cause is kept
+// if the error holding the status of gRPCErr is an *Error.
+func errorWithStatus(gRPCErr error, st *status.Status) error {
+	var cause error
+	if statusErr, ok := statusErrorFrom(gRPCErr); ok {
+		if e, ok := statusErr.(*Error); ok {
+			cause = e.cause
+		}
+	}
+
+	return &Error{st: st, cause: cause}
+}
+
+// Error returns the same string as an error returned by status.Error() would.
+func (e *Error) Error() string {
+	return fmt.Sprintf("rpc error: code = %s desc = %s", e.st.Code(), e.st.Message())
+}
+
+// GRPCStatus returns the gRPC status of the error. It's used by the gRPC framework, status.FromError()
+// and status.Convert() to obtain the status.
+func (e *Error) GRPCStatus() *status.Status {
+	return e.st
+}
+
+// Unwrap returns the Go error that caused the gRPC error, or nil if there isn't any.
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether the error matches target. The target must have a GRPCStatus() method,
+// for example an *Error or an error returned by status.Error().
+//
+// If the target holds an ErrorInfo with a reason, the errors match if their ErrorInfo reasons and
+// domains are equal. Otherwise they match if their codes are equal.
+//
+// Example:
+//
+//	var ErrTokenExpired = grpcerr.New(codes.Unauthenticated).
+//	    ErrorInfo(&grpcerr.ErrorInfo{Reason: "TOKEN_EXPIRED", Domain: "auth.example.com"}).
+//	    Err()
+//
+//	if errors.Is(err, ErrTokenExpired) {
+//	    // ...
+//	}
+func (e *Error) Is(target error) bool {
+	t, ok := target.(interface{ GRPCStatus() *status.Status })
+	if !ok {
+		return false
+	}
+	targetSt := t.GRPCStatus()
+	if targetSt == nil {
+		return false
+	}
+
+	if targetErrorInfo, ok := errorInfoDetailsFrom(targetSt); ok && targetErrorInfo.Reason != "" {
+		errorInfo, ok := errorInfoDetailsFrom(e.st)
+		return ok && errorInfo.Reason == targetErrorInfo.Reason && errorInfo.Domain == targetErrorInfo.Domain
+	}
+
+	return e.st.Code() == targetSt.Code()
+}
+
+// Format implements fmt.Formatter. The %s and %v verbs print the same string as Error(), %q prints
+// it quoted and %+v additionally prints each of the details and the cause on separate lines.
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			io.WriteString(s, e.Error())
+			for _, detail := range e.st.Proto().GetDetails() {
+				json, err := protojson.Marshal(detail)
+				if err != nil {
+					fmt.Fprintf(s, "\n    %s", detail.GetTypeUrl())
+					continue
+				}
+				fmt.Fprintf(s, "\n    %s", json)
+			}
+			if e.cause != nil {
+				fmt.Fprintf(s, "\ncaused by: %+v", e.cause)
+			}
+			return
+		}
+		io.WriteString(s, e.Error())
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
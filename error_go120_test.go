@@ -0,0 +1,30 @@
+//go:build go1.20
+// +build go1.20
+
+package grpcerr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tobbstr/testa/assert"
+	"google.golang.org/grpc/codes"
+)
+
+// Formatting with several %w verbs, and errors.Is for multi-cause errors, require Go 1.20.
+func TestErrorfWithSeveralWrapVerbs(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+	cause := errors.New("dummy-cause")
+	otherCause := errors.New("dummy-other-cause")
+
+	// When
+	got := Errorf(codes.NotFound, "dummy-msg: %w: %w", cause, otherCause)
+
+	// Then
+	assert(errors.Unwrap(got)).IsNotNil()
+	assert(errors.Is(got, cause)).IsTrue()
+	assert(errors.Is(got, otherCause)).IsTrue()
+	assert(Code(got)).Equals(codes.NotFound)
+	assert(Message(got)).Equals("dummy-msg: dummy-cause: dummy-other-cause")
+}
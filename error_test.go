@@ -0,0 +1,276 @@
+package grpcerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/tobbstr/testa/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestErrorUnwrap(t *testing.T) {
+	cause := errors.New("dummy-cause")
+	withErrorInfo := func(gRPCErr error) error {
+		got, err := AddErrorInfo(gRPCErr, &ErrorInfo{Reason: "dummy-reason"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	tests := []struct {
+		name    string
+		gRPCErr error
+		wantMsg string
+	}{
+		{
+			name:    "should unwrap to cause when get error from Errorf with %w verb",
+			gRPCErr: Errorf(codes.NotFound, "dummy-msg: %w", cause),
+			wantMsg: "dummy-msg: dummy-cause",
+		},
+		{
+			name:    "should unwrap to cause when get error from builder with cause",
+			gRPCErr: New(codes.NotFound).Message("dummy-msg").Cause(cause).Err(),
+			wantMsg: "dummy-msg",
+		},
+		{
+			name:    "should unwrap to cause when get error with details added to it",
+			gRPCErr: withErrorInfo(Errorf(codes.NotFound, "dummy-msg: %w", cause)),
+			wantMsg: "dummy-msg: dummy-cause",
+		},
+		{
+			name:    "should unwrap to cause when get wrapped error with details added to it",
+			gRPCErr: withErrorInfo(fmt.Errorf("dummy-context: %w", Errorf(codes.NotFound, "dummy-msg: %w", cause))),
+			wantMsg: "dummy-msg: dummy-cause",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+
+			// When
+			got := errors.Unwrap(tt.gRPCErr)
+
+			// Then
+			assert(got).Equals(cause)
+			assert(errors.Is(tt.gRPCErr, cause)).IsTrue()
+			assert(Code(tt.gRPCErr)).Equals(codes.NotFound)
+			assert(Message(tt.gRPCErr)).Equals(tt.wantMsg)
+		})
+	}
+}
+
+func TestErrorIs(t *testing.T) {
+	tokenExpired := New(codes.Unauthenticated).
+		ErrorInfo(&ErrorInfo{Reason: "TOKEN_EXPIRED", Domain: "dummy-domain"}).
+		Err()
+	internal, err := NewInternal("dummy-msg", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unauthenticated, err := NewUnauthenticated("dummy-msg", &ErrorInfo{Reason: "TOKEN_EXPIRED", Domain: "dummy-domain"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type args struct {
+		gRPCErr error
+		target  error
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{
+			name: "should match when get target with same code",
+			args: args{
+				gRPCErr: New(codes.NotFound).Message("dummy-msg").Err(),
+				target:  New(codes.NotFound).Err(),
+			},
+			want: true,
+		},
+		{
+			name: "should match when get status.Error target with same code",
+			args: args{
+				gRPCErr: New(codes.NotFound).Message("dummy-msg").Err(),
+				target:  status.Error(codes.NotFound, ""),
+			},
+			want: true,
+		},
+		{
+			name: "should not match when get target with different code",
+			args: args{
+				gRPCErr: New(codes.NotFound).Err(),
+				target:  New(codes.AlreadyExists).Err(),
+			},
+			want: false,
+		},
+		{
+			name: "should match when get target with same reason and domain",
+			args: args{
+				gRPCErr: New(codes.PermissionDenied).
+					ErrorInfo(&ErrorInfo{Reason: "TOKEN_EXPIRED", Domain: "dummy-domain"}).
+					Err(),
+				target: tokenExpired,
+			},
+			want: true,
+		},
+		{
+			name: "should not match when get target with same code but different reason",
+			args: args{
+				gRPCErr: New(codes.Unauthenticated).
+					ErrorInfo(&ErrorInfo{Reason: "TOKEN_MISSING", Domain: "dummy-domain"}).
+					Err(),
+				target: tokenExpired,
+			},
+			want: false,
+		},
+		{
+			name: "should not match when get target with same reason but different domain",
+			args: args{
+				gRPCErr: New(codes.Unauthenticated).
+					ErrorInfo(&ErrorInfo{Reason: "TOKEN_EXPIRED", Domain: "other-dummy-domain"}).
+					Err(),
+				target: tokenExpired,
+			},
+			want: false,
+		},
+		{
+			name: "should match when get error from constructor and target with same code",
+			args: args{
+				gRPCErr: internal,
+				target:  New(codes.Internal).Err(),
+			},
+			want: true,
+		},
+		{
+			name: "should match when get error from constructor and target with same reason and domain",
+			args: args{
+				gRPCErr: unauthenticated,
+				target:  tokenExpired,
+			},
+			want: true,
+		},
+		{
+			name: "should match when wrapped",
+			args: args{
+				gRPCErr: fmt.Errorf("dummy-wrapping-error: %w", New(codes.NotFound).Err()),
+				target:  New(codes.NotFound).Err(),
+			},
+			want: true,
+		},
+		{
+			name: "should not match when get target without GRPCStatus() method",
+			args: args{
+				gRPCErr: New(codes.NotFound).Err(),
+				target:  errors.New("dummy-error"),
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+
+			// When
+			got := errors.Is(tt.args.gRPCErr, tt.args.target)
+
+			// Then
+			assert(got).Equals(tt.want)
+		})
+	}
+}
+
+func TestErrorFormat(t *testing.T) {
+	gRPCErr := New(codes.NotFound).
+		Message("dummy-msg").
+		ErrorInfo(&ErrorInfo{Reason: "dummy-reason"}).
+		Cause(errors.New("dummy-cause")).
+		Err()
+
+	tests := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{
+			name:   "should print status when get %s verb",
+			format: "%s",
+			want:   "rpc error: code = NotFound desc = dummy-msg",
+		},
+		{
+			name:   "should print status when get %v verb",
+			format: "%v",
+			want:   "rpc error: code = NotFound desc = dummy-msg",
+		},
+		{
+			name:   "should print quoted status when get %q verb",
+			format: "%q",
+			want:   `"rpc error: code = NotFound desc = dummy-msg"`,
+		},
+		{
+			name:   "should print status, details and cause when get %+v verb",
+			format: "%+v",
+			want: "rpc error: code = NotFound desc = dummy-msg\n" +
+				`    {"@type":"type.googleapis.com/google.rpc.ErrorInfo","reason":"dummy-reason"}` + "\n" +
+				"caused by: dummy-cause",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+
+			// When
+			got := fmt.Sprintf(tt.format, gRPCErr)
+
+			// Then (protojson randomly adds whitespace, which is why it's removed before comparing)
+			assert(strings.ReplaceAll(got, " ", "")).Equals(strings.ReplaceAll(tt.want, " ", ""))
+		})
+	}
+}
+
+func TestErrorRoundTripThroughGRPCServer(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+	gRPCErr := New(codes.NotFound).
+		Message("dummy-msg").
+		ResourceInfo(&ResourceInfo{ResourceType: "dummy-resource-type", ResourceName: "dummy-resource-name"}).
+		Cause(errors.New("dummy-cause")).
+		Err()
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.UnknownServiceHandler(func(srv interface{}, stream grpc.ServerStream) error {
+		return gRPCErr
+	}))
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// When
+	got := conn.Invoke(context.Background(), "/dummy.Service/DummyMethod", &emptypb.Empty{}, &emptypb.Empty{})
+
+	// Then
+	assert(Code(got)).Equals(codes.NotFound)
+	assert(Message(got)).Equals("dummy-msg")
+	assert(ResourceInfoFrom(got)).Equals(ResourceInfo{ResourceType: "dummy-resource-type", ResourceName: "dummy-resource-name"})
+}
@@ -0,0 +1,104 @@
+package grpcerr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	cpb "google.golang.org/genproto/googleapis/rpc/code"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// googleAPIError is the error envelope used by Google APIs and grpc-gateway, where the code is the HTTP
+// status code and the status is the name of the gRPC code, for example "NOT_FOUND".
+type googleAPIError struct {
+	Error *googleAPIErrorBody `json:"error"`
+}
+
+type googleAPIErrorBody struct {
+	Code    int               `json:"code"`
+	Message string            `json:"message"`
+	Status  string            `json:"status"`
+	Details []json.RawMessage `json:"details,omitempty"`
+}
+
+// AsGoogleAPIError encodes the gRPC error as JSON using the error envelope of Google APIs, and writes it to
+// the http.ResponseWriter. Unlike AsJSON, the code is the HTTP status code and the name of the gRPC code
+// is added as the status, which allows Google-style client libraries to parse it. For example:
+//
+//	{"error": {"code": 404, "message": "user not found", "status": "NOT_FOUND", "details": [...]}}
+//
+// If the error isn't a gRPC error, it's mapped to one using FromError.
+// If an error occurs it is returned, otherwise it returns nil.
+func (f *httpResponseEncoder) AsGoogleAPIError() error {
+	return f.Encode(googleAPIErrorEncoder{})
+}
+
+// googleAPIErrorEncoder is the Encoder used by AsGoogleAPIError.
+type googleAPIErrorEncoder struct{}
+
+func (googleAPIErrorEncoder) ContentType() string {
+	return contentTypeJSON
+}
+
+func (googleAPIErrorEncoder) Encode(st *status.Status, httpStatusCode int, _ http.Header) ([]byte, error) {
+	data, err := googleAPIErrorBytesFrom(st, httpStatusCode)
+	if err != nil {
+		return nil, fmt.Errorf("could not get Google API error as bytes from gRPC status: %w", err)
+	}
+	return data, nil
+}
+
+func googleAPIErrorBytesFrom(st *status.Status, httpStatusCode int) ([]byte, error) {
+	body := &googleAPIErrorBody{
+		Code:    httpStatusCode,
+		Message: st.Message(),
+		Status:  cpb.Code(st.Code()).String(),
+	}
+	for _, detail := range st.Proto().Details {
+		data, err := protojson.Marshal(detail)
+		if err != nil {
+			return nil, err
+		}
+		body.Details = append(body.Details, data)
+	}
+
+	return json.Marshal(googleAPIError{Error: body})
+}
+
+// StatusFromGoogleAPIError returns the gRPC status held by the JSON error envelope of Google APIs, which is
+// the inverse of encoding a gRPC error using AsGoogleAPIError. The gRPC code is parsed from the status,
+// or derived from the HTTP status code if the status isn't the name of a code.
+func StatusFromGoogleAPIError(data []byte) (*status.Status, error) {
+	var envelope googleAPIError
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("could not unmarshal Google API error: %w", err)
+	}
+	if envelope.Error == nil {
+		return nil, fmt.Errorf("invalid argument: Google API error has no error member")
+	}
+	body := envelope.Error
+
+	code, ok := parseCode(body.Status)
+	if !ok && body.Code != 0 {
+		code, ok = CodeFromHTTPStatus(body.Code), true
+	}
+	if !ok || code == codes.OK {
+		return nil, fmt.Errorf("invalid argument: Google API error has neither an error status nor an error code")
+	}
+
+	st := &spb.Status{Code: int32(code), Message: body.Message}
+	for _, detail := range body.Details {
+		var anyDetail anypb.Any
+		if err := protojson.Unmarshal(detail, &anyDetail); err != nil {
+			return nil, fmt.Errorf("could not unmarshal Google API error detail: %w", err)
+		}
+		st.Details = append(st.Details, &anyDetail)
+	}
+
+	return status.FromProto(st), nil
+}
@@ -0,0 +1,133 @@
+package grpcerr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tobbstr/testa/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestHttpResponseEncodeWriteAsGoogleAPIError(t *testing.T) {
+	tests := []struct {
+		name           string
+		gRPCErr        error
+		wantStatusCode int
+		want           string
+	}{
+		{
+			name: "Should encode HTTP status code, status name and details",
+			gRPCErr: New(codes.NotFound).
+				Message("dummy-msg").
+				ResourceInfo(&ResourceInfo{ResourceType: "dummy-resource-type", ResourceName: "dummy-resource-name"}).
+				Err(),
+			wantStatusCode: http.StatusNotFound,
+			want: `{"error": {
+				"code": 404,
+				"message": "dummy-msg",
+				"status": "NOT_FOUND",
+				"details": [{"@type": "type.googleapis.com/google.rpc.ResourceInfo", "resourceType": "dummy-resource-type", "resourceName": "dummy-resource-name"}]
+			}}`,
+		},
+		{
+			name:           "Should encode CANCELLED status name when get Canceled code",
+			gRPCErr:        New(codes.Canceled).Message("dummy-msg").Err(),
+			wantStatusCode: 499,
+			want:           `{"error": {"code": 499, "message": "dummy-msg", "status": "CANCELLED"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			w := httptest.NewRecorder()
+
+			// When
+			err := NewHttpResponseEncodeWriter(w)(tt.gRPCErr).AsGoogleAPIError()
+
+			// Then
+			assert(err).IsNil()
+			assert(w.Code).Equals(tt.wantStatusCode)
+			assert(w.Header().Get("Content-Type")).Equals("application/json")
+			assert(w.Body.String()).IsJSONEqualTo(tt.want)
+		})
+	}
+}
+
+func TestStatusFromGoogleAPIError(t *testing.T) {
+	tests := []struct {
+		name             string
+		data             string
+		wantErr          bool
+		wantCode         codes.Code
+		wantMsg          string
+		wantResourceInfo ResourceInfo
+	}{
+		{
+			name: "should return status with details when get envelope encoded using AsGoogleAPIError",
+			data: `{"error": {
+				"code": 404,
+				"message": "dummy-msg",
+				"status": "NOT_FOUND",
+				"details": [{"@type": "type.googleapis.com/google.rpc.ResourceInfo", "resourceName": "dummy-resource-name"}]
+			}}`,
+			wantCode:         codes.NotFound,
+			wantMsg:          "dummy-msg",
+			wantResourceInfo: ResourceInfo{ResourceName: "dummy-resource-name"},
+		},
+		{
+			name:     "should derive code from HTTP status code when get envelope without status",
+			data:     `{"error": {"code": 429, "message": "dummy-msg"}}`,
+			wantCode: codes.ResourceExhausted,
+			wantMsg:  "dummy-msg",
+		},
+		{
+			name:    "should return error when get JSON without error member",
+			data:    `{"code": 5, "message": "dummy-msg"}`,
+			wantErr: true,
+		},
+		{
+			name:    "should return error when get detail of unknown type",
+			data:    `{"error": {"code": 404, "status": "NOT_FOUND", "details": [{"@type": "type.googleapis.com/dummy.Unknown"}]}}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+
+			// When
+			st, err := StatusFromGoogleAPIError([]byte(tt.data))
+
+			// Then
+			if tt.wantErr {
+				assert(err).IsNotNil()
+				return
+			}
+			assert(err).IsNil()
+			assert(Code(st.Err())).Equals(tt.wantCode)
+			assert(Message(st.Err())).Equals(tt.wantMsg)
+			assert(ResourceInfoFrom(st.Err())).Equals(tt.wantResourceInfo)
+		})
+	}
+}
+
+func TestFromHTTPResponseGoogleAPIError(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+	w := httptest.NewRecorder()
+	gRPCErr := New(codes.InvalidArgument).Message("dummy-msg").FieldViolations([]FieldViolation{{Field: "dummy-field", Description: "dummy-desc"}}).Err()
+	if err := NewHttpResponseEncodeWriter(w)(gRPCErr).AsGoogleAPIError(); err != nil {
+		t.Fatal(err)
+	}
+
+	// When
+	got := FromHTTPResponse(w.Result())
+
+	// Then
+	assert(Code(got)).Equals(codes.InvalidArgument)
+	assert(Message(got)).Equals("dummy-msg")
+	assert(FieldViolationsFrom(got)).Equals([]FieldViolation{{Field: "dummy-field", Description: "dummy-desc"}})
+}
@@ -2,11 +2,13 @@ package grpcerr
 
 import (
 	"fmt"
+	"time"
 
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 const (
@@ -40,17 +42,19 @@ type DebugInfo struct {
 }
 
 // AddDebugInfo adds additional debug info to a gRPC error. For example useful when the server
-// wants to include a stack trace.
+// wants to include a stack trace. If stack capture is enabled using EnableStackCapture, and debugInfo
+// has no stack entries, the stack is captured into them.
 //
 // Source: https://github.com/grpc/grpc-go/blob/master/codes/codes.go
 func AddDebugInfo(gRPCErr error, debugInfo *DebugInfo) (error, error) {
+	debugInfo = debugInfoWithStack(debugInfo, false)
 	if debugInfo == nil {
 		return gRPCErr, nil
 	}
 
-	status, ok := status.FromError(gRPCErr)
+	status, ok := StatusFrom(gRPCErr)
 	if !ok {
-		return nil, fmt.Errorf("invalid argument: gRPCErr must hold a status.Error struct")
+		return nil, fmt.Errorf("invalid argument: gRPCErr must hold a gRPC status")
 	}
 
 	errDetailsDebugInfo := &errdetails.DebugInfo{
@@ -63,13 +67,13 @@ func AddDebugInfo(gRPCErr error, debugInfo *DebugInfo) (error, error) {
 		return nil, err
 	}
 
-	return statusWithDebugInfo.Err(), nil
+	return errorWithStatus(gRPCErr, statusWithDebugInfo), nil
 }
 
 // DebugInfoFrom returns the DebugInfo from a gRPC error. If there isn't any,
 // the zero value of DebugInfo is returned.
 func DebugInfoFrom(gRPCErr error) DebugInfo {
-	st := status.Convert(gRPCErr)
+	st := convert(gRPCErr)
 
 	for _, detail := range st.Details() {
 		if debugInfo, ok := detail.(*errdetails.DebugInfo); ok {
@@ -105,9 +109,9 @@ func AddRequestInfo(gRPCErr error, requestInfo *RequestInfo) (error, error) {
 		return gRPCErr, nil
 	}
 
-	status, ok := status.FromError(gRPCErr)
+	status, ok := StatusFrom(gRPCErr)
 	if !ok {
-		return nil, fmt.Errorf("invalid argument: gRPCErr must hold a status.Error struct")
+		return nil, fmt.Errorf("invalid argument: gRPCErr must hold a gRPC status")
 	}
 
 	requestInfoDetails := errdetails.RequestInfo{
@@ -120,13 +124,13 @@ func AddRequestInfo(gRPCErr error, requestInfo *RequestInfo) (error, error) {
 		return nil, err
 	}
 
-	return statusWithInfoDetails.Err(), nil
+	return errorWithStatus(gRPCErr, statusWithInfoDetails), nil
 }
 
 // RequestInfoFrom returns the RequestInfo from a gRPC error. If there's no
 // RequestInfo details,the zero value of RequestInfo is returned.
 func RequestInfoFrom(gRPCErr error) RequestInfo {
-	st := status.Convert(gRPCErr)
+	st := convert(gRPCErr)
 
 	for _, detail := range st.Details() {
 		if requestInfo, ok := detail.(*errdetails.RequestInfo); ok {
@@ -140,6 +144,78 @@ func RequestInfoFrom(gRPCErr error) RequestInfo {
 	return RequestInfo{}
 }
 
+// Describes when the clients can retry a failed request. Clients could ignore
+// the recommendation here or retry when this information is missing from error
+// responses.
+//
+// It's always recommended that clients should use exponential backoff when
+// retrying.
+//
+// Clients should wait until `retry_delay` amount of time has passed since
+// receiving the error response before retrying. If retrying requests also
+// fail, clients should use an exponential backoff scheme to gradually increase
+// the delay between retries based on `retry_delay`, until either a maximum
+// number of retries have been reached or a maximum retry delay cap has been
+// reached.
+//
+// Source: https://pkg.go.dev/google.golang.org/genproto/googleapis/rpc/errdetails
+type RetryInfo struct {
+	// Clients should wait at least this long between retrying the same request.
+	RetryDelay time.Duration
+}
+
+// AddRetryInfo adds information to a gRPC error about when the client can retry the
+// failed request. For example useful for Unavailable and ResourceExhausted errors.
+func AddRetryInfo(gRPCErr error, retryInfo *RetryInfo) (error, error) {
+	if retryInfo == nil {
+		return gRPCErr, nil
+	}
+
+	status, ok := StatusFrom(gRPCErr)
+	if !ok {
+		return nil, fmt.Errorf("invalid argument: gRPCErr must hold a gRPC status")
+	}
+
+	retryInfoDetails := errdetails.RetryInfo{
+		RetryDelay: durationpb.New(retryInfo.RetryDelay),
+	}
+
+	statusWithRetryInfo, err := status.WithDetails(&retryInfoDetails)
+	if err != nil {
+		return nil, err
+	}
+
+	return errorWithStatus(gRPCErr, statusWithRetryInfo), nil
+}
+
+// RetryInfoFrom returns the RetryInfo from a gRPC error. If there isn't any,
+// the zero value of RetryInfo is returned.
+func RetryInfoFrom(gRPCErr error) RetryInfo {
+	st := convert(gRPCErr)
+
+	for _, detail := range st.Details() {
+		if retryInfo, ok := detail.(*errdetails.RetryInfo); ok {
+			return RetryInfo{
+				RetryDelay: retryInfo.RetryDelay.AsDuration(),
+			}
+		}
+	}
+
+	return RetryInfo{}
+}
+
+// retryInfoDetailsFrom returns the first RetryInfo details of the status. The boolean is false if
+// there isn't any.
+func retryInfoDetailsFrom(st *status.Status) (*errdetails.RetryInfo, bool) {
+	for _, detail := range st.Details() {
+		if retryInfo, ok := detail.(*errdetails.RetryInfo); ok {
+			return retryInfo, true
+		}
+	}
+
+	return nil, false
+}
+
 // Provides a link to documentation or for performing an out of band action.
 //
 // For example, if a quota check failed with an error indicating the calling
@@ -166,9 +242,9 @@ func AddHelp(gRPCErr error, links []HelpLink) (error, error) {
 		return gRPCErr, nil
 	}
 
-	status, ok := status.FromError(gRPCErr)
+	status, ok := StatusFrom(gRPCErr)
 	if !ok {
-		return nil, fmt.Errorf("invalid argument: gRPCErr must hold a status.Error struct")
+		return nil, fmt.Errorf("invalid argument: gRPCErr must hold a gRPC status")
 	}
 
 	helpDetails := errdetails.Help{}
@@ -185,13 +261,13 @@ func AddHelp(gRPCErr error, links []HelpLink) (error, error) {
 		return nil, err
 	}
 
-	return statusWithHelpDetails.Err(), nil
+	return errorWithStatus(gRPCErr, statusWithHelpDetails), nil
 }
 
 // HelpLinksFrom returns the slice of HelpLinks from a gRPC error. If there isn't any,
 // an empty slice is returned.
 func HelpLinksFrom(gRPCErr error) []HelpLink {
-	st := status.Convert(gRPCErr)
+	st := convert(gRPCErr)
 
 	for _, detail := range st.Details() {
 		if help, ok := detail.(*errdetails.Help); ok {
@@ -229,9 +305,9 @@ func AddLocalizedMessage(gRPCErr error, localizedMsg *LocalizedMessage) (error,
 		return gRPCErr, nil
 	}
 
-	status, ok := status.FromError(gRPCErr)
+	status, ok := StatusFrom(gRPCErr)
 	if !ok {
-		return nil, fmt.Errorf("invalid argument: gRPCErr must hold a status.Error struct")
+		return nil, fmt.Errorf("invalid argument: gRPCErr must hold a gRPC status")
 	}
 
 	localizedMessageDetails := errdetails.LocalizedMessage{
@@ -244,13 +320,13 @@ func AddLocalizedMessage(gRPCErr error, localizedMsg *LocalizedMessage) (error,
 		return nil, err
 	}
 
-	return statusWithLocalizedMsgDetails.Err(), nil
+	return errorWithStatus(gRPCErr, statusWithLocalizedMsgDetails), nil
 }
 
 // LocalizedMessageFrom returns the LocalizedMessage from a gRPC error. If there isn't any,
 // the zero value of LocalizedMessage is returned.
 func LocalizedMessageFrom(gRPCErr error) LocalizedMessage {
-	st := status.Convert(gRPCErr)
+	st := convert(gRPCErr)
 
 	for _, detail := range st.Details() {
 		if localizedMsg, ok := detail.(*errdetails.LocalizedMessage); ok {
@@ -288,7 +364,7 @@ func NewInvalidArgument(errMsg string, fieldViolations []FieldViolation) (error,
 	if err != nil {
 		return nil, err
 	}
-	return st.Err(), nil
+	return &Error{st: st}, nil
 }
 
 func newStatusWithBadRequestDetails(code codes.Code, errMsg string, fieldViolations []FieldViolation) (*status.Status, error) {
@@ -323,7 +399,7 @@ func newStatusWithBadRequestDetails(code codes.Code, errMsg string, fieldViolati
 // FieldViolationsFrom returns the slice of FieldViolations from a gRPC error. If there isn't any,
 // an empty slice is returned.
 func FieldViolationsFrom(gRPCErr error) []FieldViolation {
-	st := status.Convert(gRPCErr)
+	st := convert(gRPCErr)
 
 	for _, detail := range st.Details() {
 		if badReq, ok := detail.(*errdetails.BadRequest); ok {
@@ -342,8 +418,37 @@ func FieldViolationsFrom(gRPCErr error) []FieldViolation {
 	return []FieldViolation{}
 }
 
-func jsonBytesFromGrpcStatus(status *status.Status) ([]byte, error) {
-	data, err := protojson.Marshal(status.Proto())
+// AddFieldViolations adds a BadRequest detail with the field violations to a gRPC error.
+// Unlike NewInvalidArgument and NewOutOfRange it works on a gRPC error with any code.
+func AddFieldViolations(gRPCErr error, fieldViolations []FieldViolation) (error, error) {
+	if len(fieldViolations) == 0 {
+		return gRPCErr, nil
+	}
+
+	status, ok := StatusFrom(gRPCErr)
+	if !ok {
+		return nil, fmt.Errorf("invalid argument: gRPCErr must hold a gRPC status")
+	}
+
+	badRequestDetails := errdetails.BadRequest{}
+	for _, violation := range fieldViolations {
+		fv := &errdetails.BadRequest_FieldViolation{
+			Field:       violation.Field,
+			Description: violation.Description,
+		}
+		badRequestDetails.FieldViolations = append(badRequestDetails.FieldViolations, fv)
+	}
+
+	statusWithBadRequestDetails, err := status.WithDetails(&badRequestDetails)
+	if err != nil {
+		return nil, err
+	}
+
+	return errorWithStatus(gRPCErr, statusWithBadRequestDetails), nil
+}
+
+func jsonBytesFromGrpcStatus(status *status.Status, opts protojson.MarshalOptions) ([]byte, error) {
+	data, err := opts.Marshal(status.Proto())
 	if err != nil {
 		return nil, err
 	}
@@ -376,7 +481,7 @@ func NewOutOfRange(errMsg string, fieldViolations []FieldViolation) (error, erro
 	if err != nil {
 		return nil, err
 	}
-	return st.Err(), nil
+	return &Error{st: st}, nil
 }
 
 // A message type used to describe a single precondition failure.
@@ -426,7 +531,7 @@ func NewFailedPrecondition(errMsg string, failures []PreconditionFailure) (error
 	if err != nil {
 		return nil, err
 	}
-	return st.Err(), nil
+	return &Error{st: st}, nil
 }
 
 func newStatusWithFailedPreconditionDetails(code codes.Code, errMsg string, failures []PreconditionFailure) (*status.Status, error) {
@@ -457,7 +562,7 @@ func newStatusWithFailedPreconditionDetails(code codes.Code, errMsg string, fail
 // PreconditionFailuresFrom returns the slice of PreconditionFailures from a gRPC error. If there isn't any,
 // an empty slice is returned.
 func PreconditionFailuresFrom(gRPCErr error) []PreconditionFailure {
-	st := status.Convert(gRPCErr)
+	st := convert(gRPCErr)
 
 	for _, detail := range st.Details() {
 		if precondFailure, ok := detail.(*errdetails.PreconditionFailure); ok {
@@ -477,6 +582,36 @@ func PreconditionFailuresFrom(gRPCErr error) []PreconditionFailure {
 	return []PreconditionFailure{}
 }
 
+// AddPreconditionFailures adds a PreconditionFailure detail with the failures to a gRPC error.
+// Unlike NewFailedPrecondition it works on a gRPC error with any code.
+func AddPreconditionFailures(gRPCErr error, failures []PreconditionFailure) (error, error) {
+	if len(failures) == 0 {
+		return gRPCErr, nil
+	}
+
+	status, ok := StatusFrom(gRPCErr)
+	if !ok {
+		return nil, fmt.Errorf("invalid argument: gRPCErr must hold a gRPC status")
+	}
+
+	preconditionFailureDetails := errdetails.PreconditionFailure{}
+	for _, failure := range failures {
+		v := &errdetails.PreconditionFailure_Violation{
+			Type:        failure.Type,
+			Subject:     failure.Subject,
+			Description: failure.Description,
+		}
+		preconditionFailureDetails.Violations = append(preconditionFailureDetails.Violations, v)
+	}
+
+	statusWithPreconditionFailureDetails, err := status.WithDetails(&preconditionFailureDetails)
+	if err != nil {
+		return nil, err
+	}
+
+	return errorWithStatus(gRPCErr, statusWithPreconditionFailureDetails), nil
+}
+
 // Describes the cause of the error with structured details.
 //
 // Example of an error when contacting the "pubsub.googleapis.com" API when it
@@ -540,7 +675,7 @@ func NewUnauthenticated(errMsg string, errorInfo *ErrorInfo) (error, error) {
 	if err != nil {
 		return nil, err
 	}
-	return st.Err(), nil
+	return &Error{st: st}, nil
 }
 
 func newStatusWithErrorInfo(code codes.Code, errMsg string, errorInfo *ErrorInfo) (*status.Status, error) {
@@ -574,7 +709,7 @@ func newStatusWithErrorInfo(code codes.Code, errMsg string, errorInfo *ErrorInfo
 // ErrorInfoFrom returns the ErrorInfo from a gRPC error. If there isn't any,
 // the zero value of ErrorInfo is returned.
 func ErrorInfoFrom(gRPCErr error) ErrorInfo {
-	st := status.Convert(gRPCErr)
+	st := convert(gRPCErr)
 
 	for _, detail := range st.Details() {
 		if errorInfo, ok := detail.(*errdetails.ErrorInfo); ok {
@@ -589,6 +724,44 @@ func ErrorInfoFrom(gRPCErr error) ErrorInfo {
 	return ErrorInfo{}
 }
 
+// errorInfoDetailsFrom returns the first ErrorInfo details of the status. The boolean is false if
+// there isn't any.
+func errorInfoDetailsFrom(st *status.Status) (*errdetails.ErrorInfo, bool) {
+	for _, detail := range st.Details() {
+		if errorInfo, ok := detail.(*errdetails.ErrorInfo); ok {
+			return errorInfo, true
+		}
+	}
+
+	return nil, false
+}
+
+// AddErrorInfo adds an ErrorInfo detail to a gRPC error. Unlike NewUnauthenticated, NewPermissionDenied
+// and NewAborted it works on a gRPC error with any code, for example Internal or Unavailable.
+func AddErrorInfo(gRPCErr error, errorInfo *ErrorInfo) (error, error) {
+	if errorInfo == nil {
+		return gRPCErr, nil
+	}
+
+	status, ok := StatusFrom(gRPCErr)
+	if !ok {
+		return nil, fmt.Errorf("invalid argument: gRPCErr must hold a gRPC status")
+	}
+
+	errorInfoDetails := errdetails.ErrorInfo{
+		Reason:   errorInfo.Reason,
+		Domain:   errorInfo.Domain,
+		Metadata: errorInfo.Metadata,
+	}
+
+	statusWithErrorInfo, err := status.WithDetails(&errorInfoDetails)
+	if err != nil {
+		return nil, err
+	}
+
+	return errorWithStatus(gRPCErr, statusWithErrorInfo), nil
+}
+
 // NewPermissionDenied constructs a gRPC error that indicates the caller does not have permission to
 // execute the specified operation. It must not be used for rejections
 // caused by exhausting some resource (use ResourceExhausted
@@ -605,7 +778,7 @@ func NewPermissionDenied(errMsg string, errorInfo *ErrorInfo) (error, error) {
 	if err != nil {
 		return nil, err
 	}
-	return st.Err(), nil
+	return &Error{st: st}, nil
 }
 
 // NewAborted constructs a gRPC error that indicates the operation was aborted, typically due to a
@@ -623,7 +796,7 @@ func NewAborted(errMsg string, errorInfo *ErrorInfo) (error, error) {
 	if err != nil {
 		return nil, err
 	}
-	return st.Err(), nil
+	return &Error{st: st}, nil
 }
 
 // Describes the resource that is being accessed.
@@ -659,7 +832,7 @@ func NewNotFound(errMsg string, resourceInfo *ResourceInfo) (error, error) {
 	if err != nil {
 		return nil, err
 	}
-	return st.Err(), nil
+	return &Error{st: st}, nil
 }
 
 // NewAlreadyExists constructs a gRPC error that means an attempt to create an entity failed because one
@@ -673,7 +846,7 @@ func NewAlreadyExists(errMsg string, resourceInfo *ResourceInfo) (error, error)
 	if err != nil {
 		return nil, err
 	}
-	return st.Err(), nil
+	return &Error{st: st}, nil
 }
 
 func newStatusWithResourceInfo(code codes.Code, errMsg string, resourceInfo *ResourceInfo) (*status.Status, error) {
@@ -706,7 +879,7 @@ func newStatusWithResourceInfo(code codes.Code, errMsg string, resourceInfo *Res
 // ResourceInfoFrom returns the ResourceInfo from a gRPC error. If there isn't any,
 // the zero value of ResourceInfo is returned.
 func ResourceInfoFrom(gRPCErr error) ResourceInfo {
-	st := status.Convert(gRPCErr)
+	st := convert(gRPCErr)
 
 	for _, detail := range st.Details() {
 		if resourceInfo, ok := detail.(*errdetails.ResourceInfo); ok {
@@ -722,6 +895,33 @@ func ResourceInfoFrom(gRPCErr error) ResourceInfo {
 	return ResourceInfo{}
 }
 
+// AddResourceInfo adds a ResourceInfo detail to a gRPC error. Unlike NewNotFound and NewAlreadyExists
+// it works on a gRPC error with any code, for example PermissionDenied.
+func AddResourceInfo(gRPCErr error, resourceInfo *ResourceInfo) (error, error) {
+	if resourceInfo == nil {
+		return gRPCErr, nil
+	}
+
+	status, ok := StatusFrom(gRPCErr)
+	if !ok {
+		return nil, fmt.Errorf("invalid argument: gRPCErr must hold a gRPC status")
+	}
+
+	resourceInfoDetails := errdetails.ResourceInfo{
+		ResourceType: resourceInfo.ResourceType,
+		ResourceName: resourceInfo.ResourceName,
+		Owner:        resourceInfo.Owner,
+		Description:  resourceInfo.Description,
+	}
+
+	statusWithResourceInfo, err := status.WithDetails(&resourceInfoDetails)
+	if err != nil {
+		return nil, err
+	}
+
+	return errorWithStatus(gRPCErr, statusWithResourceInfo), nil
+}
+
 // A message type used to describe a single quota violation.  For example, a
 // daily quota or a custom quota that was exceeded.
 //
@@ -754,7 +954,7 @@ func NewResourceExhausted(errMsg string, quotaViolations []QuotaViolation) (erro
 	if err != nil {
 		return nil, err
 	}
-	return st.Err(), nil
+	return &Error{st: st}, nil
 }
 
 func newStatusWithQuotaFailure(code codes.Code, errMsg string, violations []QuotaViolation) (*status.Status, error) {
@@ -784,7 +984,7 @@ func newStatusWithQuotaFailure(code codes.Code, errMsg string, violations []Quot
 // QuotaViolationsFrom returns the slice of QuotaViolations from a gRPC error. If there isn't any,
 // an empty slice is returned.
 func QuotaViolationsFrom(gRPCErr error) []QuotaViolation {
-	st := status.Convert(gRPCErr)
+	st := convert(gRPCErr)
 
 	for _, detail := range st.Details() {
 		if quotaFailure, ok := detail.(*errdetails.QuotaFailure); ok {
@@ -803,6 +1003,35 @@ func QuotaViolationsFrom(gRPCErr error) []QuotaViolation {
 	return []QuotaViolation{}
 }
 
+// AddQuotaViolations adds a QuotaFailure detail with the violations to a gRPC error.
+// Unlike NewResourceExhausted it works on a gRPC error with any code.
+func AddQuotaViolations(gRPCErr error, violations []QuotaViolation) (error, error) {
+	if len(violations) == 0 {
+		return gRPCErr, nil
+	}
+
+	status, ok := StatusFrom(gRPCErr)
+	if !ok {
+		return nil, fmt.Errorf("invalid argument: gRPCErr must hold a gRPC status")
+	}
+
+	quotaFailureDetails := errdetails.QuotaFailure{}
+	for _, violation := range violations {
+		v := &errdetails.QuotaFailure_Violation{
+			Subject:     violation.Subject,
+			Description: violation.Description,
+		}
+		quotaFailureDetails.Violations = append(quotaFailureDetails.Violations, v)
+	}
+
+	statusWithQuotaFailureDetails, err := status.WithDetails(&quotaFailureDetails)
+	if err != nil {
+		return nil, err
+	}
+
+	return errorWithStatus(gRPCErr, statusWithQuotaFailureDetails), nil
+}
+
 // NewCancelled constructs a gRPC error that indicates the operation was canceled (typically by the caller).
 //
 // The gRPC framework will generate this error code when cancellation
@@ -817,15 +1046,19 @@ func NewCancelled(errMsg string) error {
 		st = status.New(codes.Canceled, errMsg)
 	}
 
-	return st.Err()
+	return &Error{st: st}
 }
 
 // NewDataLoss constructs a gRPC error that indicates unrecoverable data loss or corruption.
 //
 // This error code will not be generated by the gRPC framework.
 //
+// If stack capture is enabled using EnableStackCapture, the stack is captured into the DebugInfo.
+//
 // Source: https://github.com/grpc/grpc-go/blob/master/codes/codes.go
 func NewDataLoss(errMsg string, debugInfo *DebugInfo) (error, error) {
+	debugInfo = debugInfoWithStack(debugInfo, true)
+
 	var st *status.Status
 	if errMsg == "" {
 		st = status.New(codes.DataLoss, defaultCanceledErrMsg)
@@ -834,7 +1067,7 @@ func NewDataLoss(errMsg string, debugInfo *DebugInfo) (error, error) {
 	}
 
 	if debugInfo == nil {
-		return st.Err(), nil
+		return &Error{st: st}, nil
 	}
 
 	debugInfoDetails := errdetails.DebugInfo{
@@ -847,7 +1080,7 @@ func NewDataLoss(errMsg string, debugInfo *DebugInfo) (error, error) {
 		return nil, err
 	}
 
-	return statusWithDetails.Err(), nil
+	return &Error{st: statusWithDetails}, nil
 }
 
 func newStatusWithDebugInfo(code codes.Code, errMsg string, debugInfo *DebugInfo) (*status.Status, error) {
@@ -891,13 +1124,17 @@ func newStatusWithDebugInfo(code codes.Code, errMsg string, debugInfo *DebugInfo
 // The gRPC framework will generate this error code in the above two
 // mentioned cases.
 //
+// If stack capture is enabled using EnableStackCapture, the stack is captured into the DebugInfo.
+//
 // Source: https://github.com/grpc/grpc-go/blob/master/codes/codes.go
 func NewUnknown(errMsg string, debugInfo *DebugInfo) (error, error) {
+	debugInfo = debugInfoWithStack(debugInfo, true)
+
 	st, err := newStatusWithDebugInfo(codes.Unknown, errMsg, debugInfo)
 	if err != nil {
 		return nil, err
 	}
-	return st.Err(), nil
+	return &Error{st: st}, nil
 }
 
 // NewInternal construct a gRPC error that means some invariants expected by underlying
@@ -907,13 +1144,17 @@ func NewUnknown(errMsg string, debugInfo *DebugInfo) (error, error) {
 // This error code will be generated by the gRPC framework in several
 // internal error conditions.
 //
+// If stack capture is enabled using EnableStackCapture, the stack is captured into the DebugInfo.
+//
 // Source: https://github.com/grpc/grpc-go/blob/master/codes/codes.go
 func NewInternal(errMsg string, debugInfo *DebugInfo) (error, error) {
+	debugInfo = debugInfoWithStack(debugInfo, true)
+
 	st, err := newStatusWithDebugInfo(codes.Internal, errMsg, debugInfo)
 	if err != nil {
 		return nil, err
 	}
-	return st.Err(), nil
+	return &Error{st: st}, nil
 }
 
 // NewUnimplemented constructs a gRPC error that indicates operation is not implemented or not
@@ -934,7 +1175,7 @@ func NewUnimplemented(errMsg string) error {
 		st = status.New(codes.Unimplemented, errMsg)
 	}
 
-	return st.Err()
+	return &Error{st: st}
 }
 
 // NewUnavailable constructs a gRPC error that indicates the service is currently unavailable.
@@ -954,7 +1195,7 @@ func NewUnavailable(errMsg string, debugInfo *DebugInfo) (error, error) {
 	if err != nil {
 		return nil, err
 	}
-	return st.Err(), nil
+	return &Error{st: st}, nil
 }
 
 // NewDeadlineExceeded constructs a gRPC error that means operation expired before completion.
@@ -972,15 +1213,15 @@ func NewDeadlineExceeded(errMsg string, debugInfo *DebugInfo) (error, error) {
 	if err != nil {
 		return nil, err
 	}
-	return st.Err(), nil
+	return &Error{st: st}, nil
 }
 
 func Code(gRPCErr error) codes.Code {
-	st := status.Convert(gRPCErr)
+	st := convert(gRPCErr)
 	return st.Code()
 }
 
 func Message(gRPCErr error) string {
-	st := status.Convert(gRPCErr)
+	st := convert(gRPCErr)
 	return st.Message()
 }
@@ -3,11 +3,13 @@ package grpcerr
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/tobbstr/testa/assert"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 func TestAddDebugInfo(t *testing.T) {
@@ -87,7 +89,7 @@ func TestAddDebugInfo(t *testing.T) {
 
 			// Then
 			assert(err).IsWantedError(tt.wantErr)
-			assert(got).Equals(tt.want)
+			assert(status.Convert(got).Proto()).Equals(status.Convert(tt.want).Proto())
 		})
 	}
 }
@@ -237,7 +239,7 @@ func TestAddRequestInfo(t *testing.T) {
 
 			// Then
 			assert(err).IsWantedError(tt.wantErr)
-			assert(got).Equals(tt.want)
+			assert(status.Convert(got).Proto()).Equals(status.Convert(tt.want).Proto())
 		})
 	}
 }
@@ -303,6 +305,124 @@ func TestRequestInfoFrom(t *testing.T) {
 	}
 }
 
+func TestAddRetryInfo(t *testing.T) {
+	validGRPCErr := NewUnimplemented("dummy-err-msg")
+
+	statusWithRetryInfo := status.New(codes.Unimplemented, "dummy-err-msg")
+	ri := errdetails.RetryInfo{
+		RetryDelay: durationpb.New(3 * time.Second),
+	}
+	statusWithRetryInfo, err := statusWithRetryInfo.WithDetails(&ri)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gRPCErrWithRetryInfo := statusWithRetryInfo.Err()
+
+	type args struct {
+		gRPCErr   error
+		retryInfo *RetryInfo
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    error
+		wantErr bool
+	}{
+		{
+			name: "should return gRPC error with RetryInfo for valid arguments",
+			args: args{
+				gRPCErr:   validGRPCErr,
+				retryInfo: &RetryInfo{RetryDelay: 3 * time.Second},
+			},
+			want:    gRPCErrWithRetryInfo,
+			wantErr: false,
+		},
+		{
+			name: "should return error when get gRPCErr which does not have a GRPCStatus() method",
+			args: args{
+				gRPCErr:   fmt.Errorf("dummy-error"),
+				retryInfo: &RetryInfo{RetryDelay: 3 * time.Second},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "should return same gRPCErr when get nil retryInfo",
+			args: args{
+				gRPCErr:   validGRPCErr,
+				retryInfo: nil,
+			},
+			want:    validGRPCErr,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+
+			// When
+			got, err := AddRetryInfo(tt.args.gRPCErr, tt.args.retryInfo)
+
+			// Then
+			assert(err).IsWantedError(tt.wantErr)
+			assert(status.Convert(got).Proto()).Equals(status.Convert(tt.want).Proto())
+		})
+	}
+}
+
+func TestRetryInfoFrom(t *testing.T) {
+	retryInfoDetails := &errdetails.RetryInfo{
+		RetryDelay: durationpb.New(1500 * time.Millisecond),
+	}
+
+	status := status.New(codes.Unavailable, defaultUnavailableErrMsg)
+	gRPCErrWithoutRetryInfo := status.Err()
+
+	statusWithRetryInfo, err := status.WithDetails(retryInfoDetails)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gRPCErrWithRetryInfo := statusWithRetryInfo.Err()
+
+	type args struct {
+		gRPCErr error
+	}
+	tests := []struct {
+		name string
+		args args
+		want RetryInfo
+	}{
+		{
+			name: "Should return RetryInfo when get gRPCErr with retryInfoDetails",
+			args: args{
+				gRPCErr: gRPCErrWithRetryInfo,
+			},
+			want: RetryInfo{RetryDelay: 1500 * time.Millisecond},
+		},
+		{
+			name: "Should return zero RetryInfo when get gRPCErr without retryInfoDetails",
+			args: args{
+				gRPCErr: gRPCErrWithoutRetryInfo,
+			},
+			want: RetryInfo{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+
+			// When
+			got := RetryInfoFrom(tt.args.gRPCErr)
+
+			// Then
+			assert(got).Equals(tt.want)
+		})
+	}
+}
+
 func TestAddHelp(t *testing.T) {
 	unimplemented := NewUnimplemented("dummy-msg")
 
@@ -380,7 +500,7 @@ func TestAddHelp(t *testing.T) {
 
 			// Then
 			assert(err).IsWantedError(tt.wantErr)
-			assert(got).Equals(tt.want)
+			assert(status.Convert(got).Proto()).Equals(status.Convert(tt.want).Proto())
 		})
 	}
 }
@@ -529,7 +649,7 @@ func TestAddLocalizedMessage(t *testing.T) {
 
 			// Then
 			assert(err).IsWantedError(tt.wantErr)
-			assert(got).Equals(tt.want)
+			assert(status.Convert(got).Proto()).Equals(status.Convert(tt.want).Proto())
 		})
 	}
 }
@@ -672,7 +792,7 @@ func TestNewInvalidArgument(t *testing.T) {
 
 			// Then
 			assert(err).IsWantedError(tt.wantErr)
-			assert(got).Equals(tt.want)
+			assert(status.Convert(got).Proto()).Equals(status.Convert(tt.want).Proto())
 		})
 	}
 }
@@ -1118,7 +1238,7 @@ func TestNewOutOfRange(t *testing.T) {
 
 			// Then
 			assert(err).IsWantedError(tt.wantErr)
-			assert(got).Equals(tt.want)
+			assert(status.Convert(got).Proto()).Equals(status.Convert(tt.want).Proto())
 		})
 	}
 }
@@ -1473,3 +1593,338 @@ func TestMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestAddFieldViolations(t *testing.T) {
+	validGRPCErr, err := NewUnavailable("dummy-err-msg", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statusWithFieldViolations := status.New(codes.Unavailable, "dummy-err-msg")
+	statusWithFieldViolations, err = statusWithFieldViolations.WithDetails(&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "dummy-field", Description: "dummy-description"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gRPCErrWithFieldViolations := statusWithFieldViolations.Err()
+
+	type args struct {
+		gRPCErr         error
+		fieldViolations []FieldViolation
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    error
+		wantErr bool
+	}{
+		{
+			name: "should return gRPC error with field violations for valid arguments",
+			args: args{
+				gRPCErr:         validGRPCErr,
+				fieldViolations: []FieldViolation{{Field: "dummy-field", Description: "dummy-description"}},
+			},
+			want:    gRPCErrWithFieldViolations,
+			wantErr: false,
+		},
+		{
+			name: "should return error when get gRPCErr which does not have a GRPCStatus() method",
+			args: args{
+				gRPCErr:         fmt.Errorf("dummy-error"),
+				fieldViolations: []FieldViolation{{Field: "dummy-field", Description: "dummy-description"}},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "should return same gRPCErr when get empty fieldViolations",
+			args: args{
+				gRPCErr:         validGRPCErr,
+				fieldViolations: nil,
+			},
+			want:    validGRPCErr,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+
+			// When
+			got, err := AddFieldViolations(tt.args.gRPCErr, tt.args.fieldViolations)
+
+			// Then
+			assert(err).IsWantedError(tt.wantErr)
+			assert(status.Convert(got).Proto()).Equals(status.Convert(tt.want).Proto())
+		})
+	}
+}
+
+func TestAddPreconditionFailures(t *testing.T) {
+	validGRPCErr, err := NewUnavailable("dummy-err-msg", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statusWithPreconditionFailures := status.New(codes.Unavailable, "dummy-err-msg")
+	statusWithPreconditionFailures, err = statusWithPreconditionFailures.WithDetails(&errdetails.PreconditionFailure{Violations: []*errdetails.PreconditionFailure_Violation{{Type: "dummy-type", Subject: "dummy-subject", Description: "dummy-description"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gRPCErrWithPreconditionFailures := statusWithPreconditionFailures.Err()
+
+	type args struct {
+		gRPCErr  error
+		failures []PreconditionFailure
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    error
+		wantErr bool
+	}{
+		{
+			name: "should return gRPC error with precondition failures for valid arguments",
+			args: args{
+				gRPCErr:  validGRPCErr,
+				failures: []PreconditionFailure{{Type: "dummy-type", Subject: "dummy-subject", Description: "dummy-description"}},
+			},
+			want:    gRPCErrWithPreconditionFailures,
+			wantErr: false,
+		},
+		{
+			name: "should return error when get gRPCErr which does not have a GRPCStatus() method",
+			args: args{
+				gRPCErr:  fmt.Errorf("dummy-error"),
+				failures: []PreconditionFailure{{Type: "dummy-type", Subject: "dummy-subject", Description: "dummy-description"}},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "should return same gRPCErr when get empty failures",
+			args: args{
+				gRPCErr:  validGRPCErr,
+				failures: nil,
+			},
+			want:    validGRPCErr,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+
+			// When
+			got, err := AddPreconditionFailures(tt.args.gRPCErr, tt.args.failures)
+
+			// Then
+			assert(err).IsWantedError(tt.wantErr)
+			assert(status.Convert(got).Proto()).Equals(status.Convert(tt.want).Proto())
+		})
+	}
+}
+
+func TestAddErrorInfo(t *testing.T) {
+	validGRPCErr, err := NewUnavailable("dummy-err-msg", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statusWithErrorInfo := status.New(codes.Unavailable, "dummy-err-msg")
+	statusWithErrorInfo, err = statusWithErrorInfo.WithDetails(&errdetails.ErrorInfo{Reason: "dummy-reason", Domain: "dummy-domain", Metadata: map[string]string{"dummy-key": "dummy-value"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gRPCErrWithErrorInfo := statusWithErrorInfo.Err()
+
+	type args struct {
+		gRPCErr   error
+		errorInfo *ErrorInfo
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    error
+		wantErr bool
+	}{
+		{
+			name: "should return gRPC error with ErrorInfo for valid arguments",
+			args: args{
+				gRPCErr:   validGRPCErr,
+				errorInfo: &ErrorInfo{Reason: "dummy-reason", Domain: "dummy-domain", Metadata: map[string]string{"dummy-key": "dummy-value"}},
+			},
+			want:    gRPCErrWithErrorInfo,
+			wantErr: false,
+		},
+		{
+			name: "should return error when get gRPCErr which does not have a GRPCStatus() method",
+			args: args{
+				gRPCErr:   fmt.Errorf("dummy-error"),
+				errorInfo: &ErrorInfo{Reason: "dummy-reason", Domain: "dummy-domain", Metadata: map[string]string{"dummy-key": "dummy-value"}},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "should return same gRPCErr when get nil errorInfo",
+			args: args{
+				gRPCErr:   validGRPCErr,
+				errorInfo: nil,
+			},
+			want:    validGRPCErr,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+
+			// When
+			got, err := AddErrorInfo(tt.args.gRPCErr, tt.args.errorInfo)
+
+			// Then
+			assert(err).IsWantedError(tt.wantErr)
+			assert(status.Convert(got).Proto()).Equals(status.Convert(tt.want).Proto())
+		})
+	}
+}
+
+func TestAddResourceInfo(t *testing.T) {
+	validGRPCErr, err := NewUnavailable("dummy-err-msg", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statusWithResourceInfo := status.New(codes.Unavailable, "dummy-err-msg")
+	statusWithResourceInfo, err = statusWithResourceInfo.WithDetails(&errdetails.ResourceInfo{ResourceType: "dummy-resource-type", ResourceName: "dummy-resource-name", Owner: "dummy-owner", Description: "dummy-description"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gRPCErrWithResourceInfo := statusWithResourceInfo.Err()
+
+	type args struct {
+		gRPCErr      error
+		resourceInfo *ResourceInfo
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    error
+		wantErr bool
+	}{
+		{
+			name: "should return gRPC error with ResourceInfo for valid arguments",
+			args: args{
+				gRPCErr:      validGRPCErr,
+				resourceInfo: &ResourceInfo{ResourceType: "dummy-resource-type", ResourceName: "dummy-resource-name", Owner: "dummy-owner", Description: "dummy-description"},
+			},
+			want:    gRPCErrWithResourceInfo,
+			wantErr: false,
+		},
+		{
+			name: "should return error when get gRPCErr which does not have a GRPCStatus() method",
+			args: args{
+				gRPCErr:      fmt.Errorf("dummy-error"),
+				resourceInfo: &ResourceInfo{ResourceType: "dummy-resource-type", ResourceName: "dummy-resource-name", Owner: "dummy-owner", Description: "dummy-description"},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "should return same gRPCErr when get nil resourceInfo",
+			args: args{
+				gRPCErr:      validGRPCErr,
+				resourceInfo: nil,
+			},
+			want:    validGRPCErr,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+
+			// When
+			got, err := AddResourceInfo(tt.args.gRPCErr, tt.args.resourceInfo)
+
+			// Then
+			assert(err).IsWantedError(tt.wantErr)
+			assert(status.Convert(got).Proto()).Equals(status.Convert(tt.want).Proto())
+		})
+	}
+}
+
+func TestAddQuotaViolations(t *testing.T) {
+	validGRPCErr, err := NewUnavailable("dummy-err-msg", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statusWithQuotaViolations := status.New(codes.Unavailable, "dummy-err-msg")
+	statusWithQuotaViolations, err = statusWithQuotaViolations.WithDetails(&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{Subject: "dummy-subject", Description: "dummy-description"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gRPCErrWithQuotaViolations := statusWithQuotaViolations.Err()
+
+	type args struct {
+		gRPCErr    error
+		violations []QuotaViolation
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    error
+		wantErr bool
+	}{
+		{
+			name: "should return gRPC error with quota violations for valid arguments",
+			args: args{
+				gRPCErr:    validGRPCErr,
+				violations: []QuotaViolation{{Subject: "dummy-subject", Description: "dummy-description"}},
+			},
+			want:    gRPCErrWithQuotaViolations,
+			wantErr: false,
+		},
+		{
+			name: "should return error when get gRPCErr which does not have a GRPCStatus() method",
+			args: args{
+				gRPCErr:    fmt.Errorf("dummy-error"),
+				violations: []QuotaViolation{{Subject: "dummy-subject", Description: "dummy-description"}},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "should return same gRPCErr when get empty violations",
+			args: args{
+				gRPCErr:    validGRPCErr,
+				violations: nil,
+			},
+			want:    validGRPCErr,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+
+			// When
+			got, err := AddQuotaViolations(tt.args.gRPCErr, tt.args.violations)
+
+			// Then
+			assert(err).IsWantedError(tt.wantErr)
+			assert(status.Convert(got).Proto()).Equals(status.Convert(tt.want).Proto())
+		})
+	}
+}
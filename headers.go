@@ -0,0 +1,207 @@
+package grpcerr
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	cpb "google.golang.org/genproto/googleapis/rpc/code"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// The headers into which WithErrorHeaders mirrors the error metadata.
+const (
+	// HeaderErrorCode holds the name of the gRPC code, for example "NOT_FOUND".
+	HeaderErrorCode = "X-Error-Code"
+	// HeaderErrorReason holds the ErrorInfo's reason.
+	HeaderErrorReason = "X-Error-Reason"
+	// HeaderErrorDomain holds the ErrorInfo's domain.
+	HeaderErrorDomain = "X-Error-Domain"
+	// HeaderErrorRetryDelay holds the RetryInfo's retry delay in seconds, for example "1.5s".
+	HeaderErrorRetryDelay = "X-Error-Retry-Delay"
+	// HeaderRequestID holds the RequestInfo's request ID.
+	HeaderRequestID = "X-Request-ID"
+)
+
+// The gRPC-native headers emitted by WithGRPCStatusHeaders.
+const (
+	// HeaderGRPCStatus holds the gRPC code as a decimal number.
+	HeaderGRPCStatus = "grpc-status"
+	// HeaderGRPCMessage holds the percent-encoded message.
+	HeaderGRPCMessage = "grpc-message"
+	// HeaderGRPCStatusDetailsBin holds the base64 encoded binary google.rpc.Status, including its details.
+	HeaderGRPCStatusDetailsBin = "grpc-status-details-bin"
+)
+
+// WithErrorHeaders mirrors the error metadata into response headers, which allows gateways and CDNs which
+// can't parse the body to route and alert on the error. The headers are:
+//
+//	X-Error-Code         the name of the gRPC code, for example "NOT_FOUND"
+//	X-Error-Reason       the ErrorInfo's reason
+//	X-Error-Domain       the ErrorInfo's domain
+//	X-Error-Retry-Delay  the RetryInfo's retry delay in seconds, for example "1.5s"
+//	X-Request-ID         the RequestInfo's request ID
+//
+// Headers whose values are missing from the gRPC error aren't set. They're parsed by StatusFromHeaders.
+// The option does nothing unless it's given a *StatusResponseWriter, as it is by the HTTP response encoder.
+func WithErrorHeaders() ResponseWriterOption {
+	return withStatusResponseWriter(func(w *StatusResponseWriter) {
+		header := w.Header()
+		header.Set(HeaderErrorCode, cpb.Code(w.Status.Code()).String())
+
+		if errorInfo, ok := errorInfoDetailsFrom(w.Status); ok {
+			setHeaderIfNotEmpty(header, HeaderErrorReason, errorInfo.Reason)
+			setHeaderIfNotEmpty(header, HeaderErrorDomain, errorInfo.Domain)
+		}
+		if retryInfo, ok := retryInfoDetailsFrom(w.Status); ok {
+			header.Set(HeaderErrorRetryDelay, strconv.FormatFloat(retryInfo.RetryDelay.AsDuration().Seconds(), 'f', -1, 64)+"s")
+		}
+		setHeaderIfNotEmpty(header, HeaderRequestID, RequestInfoFrom(w.Status.Err()).RequestID)
+	})
+}
+
+// WithGRPCStatusHeaders emits the gRPC-native grpc-status, grpc-message and grpc-status-details-bin headers,
+// which hold the complete gRPC status, including its details. They're parsed by StatusFromHeaders.
+// The option does nothing unless it's given a *StatusResponseWriter, as it is by the HTTP response encoder.
+func WithGRPCStatusHeaders() ResponseWriterOption {
+	return withStatusResponseWriter(func(w *StatusResponseWriter) {
+		header := w.Header()
+		// The headers are set directly since gRPC uses lowercase header names
+		header[HeaderGRPCStatus] = []string{strconv.Itoa(int(w.Status.Code()))}
+		if w.Status.Message() != "" {
+			header[HeaderGRPCMessage] = []string{encodeGRPCMessage(w.Status.Message())}
+		}
+		if data, err := proto.Marshal(w.Status.Proto()); err == nil {
+			header[HeaderGRPCStatusDetailsBin] = []string{base64.RawStdEncoding.EncodeToString(data)}
+		}
+	})
+}
+
+func setHeaderIfNotEmpty(header http.Header, key, value string) {
+	if value != "" {
+		header.Set(key, value)
+	}
+}
+
+// StatusFromHeaders returns the gRPC status held by the response headers, which is the inverse of
+// encoding a gRPC error using WithGRPCStatusHeaders or WithErrorHeaders. The gRPC-native headers are
+// preferred since they hold the complete status. Otherwise the status is built from the X-Error-* headers,
+// without a message. An error is returned if the headers don't hold a gRPC status.
+//
+// Example:
+//
+//	st, err := grpcerr.StatusFromHeaders(resp.Header)
+func StatusFromHeaders(header http.Header) (*status.Status, error) {
+	if st, ok, err := statusFromGRPCHeaders(header); ok || err != nil {
+		return st, err
+	}
+	if header.Get(HeaderErrorCode) != "" {
+		return statusFromErrorHeaders(header)
+	}
+
+	return nil, fmt.Errorf("invalid argument: headers hold no gRPC status")
+}
+
+// statusFromGRPCHeaders returns the status held by the gRPC-native headers. It also returns whether there
+// were any.
+func statusFromGRPCHeaders(header http.Header) (*status.Status, bool, error) {
+	if details := headerValue(header, HeaderGRPCStatusDetailsBin); details != "" {
+		// gRPC uses unpadded base64, but padded base64 is accepted too
+		data, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(details, "="))
+		if err != nil {
+			return nil, true, fmt.Errorf("could not decode %s header: %w", HeaderGRPCStatusDetailsBin, err)
+		}
+		st, ok := statusFrom(data, proto.Unmarshal)
+		if !ok {
+			return nil, true, fmt.Errorf("invalid argument: %s header holds no gRPC error", HeaderGRPCStatusDetailsBin)
+		}
+		return st, true, nil
+	}
+
+	value := headerValue(header, HeaderGRPCStatus)
+	if value == "" {
+		return nil, false, nil
+	}
+	code, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return nil, true, fmt.Errorf("invalid argument: %s header %q is not a number", HeaderGRPCStatus, value)
+	}
+	if !isErrorCode(codes.Code(code)) {
+		return nil, true, fmt.Errorf("invalid argument: %s header %q is not a gRPC error code", HeaderGRPCStatus, value)
+	}
+
+	st := &spb.Status{Code: int32(code), Message: decodeGRPCMessage(headerValue(header, HeaderGRPCMessage))}
+	return status.FromProto(st), true, nil
+}
+
+// statusFromErrorHeaders returns the status held by the X-Error-* headers.
+func statusFromErrorHeaders(header http.Header) (*status.Status, error) {
+	value := header.Get(HeaderErrorCode)
+	code, ok := parseCode(value)
+	if !ok || code == codes.OK {
+		return nil, fmt.Errorf("invalid argument: %s header %q is not a gRPC error code", HeaderErrorCode, value)
+	}
+
+	b := New(code)
+	if reason, domain := header.Get(HeaderErrorReason), header.Get(HeaderErrorDomain); reason != "" || domain != "" {
+		b.ErrorInfo(&ErrorInfo{Reason: reason, Domain: domain})
+	}
+	if value := header.Get(HeaderErrorRetryDelay); value != "" {
+		delay, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument: %s header %q is not a duration", HeaderErrorRetryDelay, value)
+		}
+		b.RetryInfo(&RetryInfo{RetryDelay: delay})
+	}
+	if requestID := header.Get(HeaderRequestID); requestID != "" {
+		b.RequestInfo(&RequestInfo{RequestID: requestID})
+	}
+
+	return b.Status(), nil
+}
+
+// headerValue returns the first value of the header. Unlike http.Header.Get, it also finds lowercase
+// header names which were set directly rather than canonicalized.
+func headerValue(header http.Header, key string) string {
+	if values := header[key]; len(values) > 0 {
+		return values[0]
+	}
+	return header.Get(key)
+}
+
+// encodeGRPCMessage percent-encodes the message as required by the gRPC protocol, which allows only
+// printable ASCII characters other than '%' in the grpc-message header.
+func encodeGRPCMessage(msg string) string {
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c >= ' ' && c <= '~' && c != '%' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// decodeGRPCMessage decodes a percent-encoded grpc-message header. Malformed escapes are kept as is.
+func decodeGRPCMessage(msg string) string {
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		if msg[i] == '%' && i+2 < len(msg) {
+			if c, err := strconv.ParseUint(msg[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(msg[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,207 @@
+package grpcerr
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tobbstr/testa/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestHttpResponseEncodeWriteWithErrorHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		gRPCErr error
+		want    http.Header
+	}{
+		{
+			name: "Should mirror error metadata into headers",
+			gRPCErr: New(codes.Unavailable).
+				ErrorInfo(&ErrorInfo{Reason: "dummy-reason", Domain: "dummy-domain"}).
+				RequestInfo(&RequestInfo{RequestID: "dummy-request-id"}).
+				RetryInfo(&RetryInfo{RetryDelay: 1500 * time.Millisecond}).
+				Err(),
+			want: http.Header{
+				"X-Error-Code":        []string{"UNAVAILABLE"},
+				"X-Error-Reason":      []string{"dummy-reason"},
+				"X-Error-Domain":      []string{"dummy-domain"},
+				"X-Error-Retry-Delay": []string{"1.5s"},
+				"X-Request-Id":        []string{"dummy-request-id"},
+			},
+		},
+		{
+			name:    "Should only set code header when there is no metadata",
+			gRPCErr: New(codes.NotFound).Err(),
+			want:    http.Header{"X-Error-Code": []string{"NOT_FOUND"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			w := httptest.NewRecorder()
+
+			// When
+			err := NewHttpResponseEncodeWriter(w, WithErrorHeaders())(tt.gRPCErr).AsJSON()
+
+			// Then
+			assert(err).IsNil()
+			for key := range w.Header() {
+				if !strings.HasPrefix(key, "X-") {
+					delete(w.Header(), key)
+				}
+			}
+			assert(w.Header()).Equals(tt.want)
+		})
+	}
+}
+
+func TestHttpResponseEncodeWriteWithGRPCStatusHeaders(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+	w := httptest.NewRecorder()
+	gRPCErr := New(codes.InvalidArgument).Message("dummy-msg 100% ü\n").ErrorInfo(&ErrorInfo{Reason: "dummy-reason"}).Err()
+
+	// When
+	err := NewHttpResponseEncodeWriter(w, WithGRPCStatusHeaders())(gRPCErr).AsJSON()
+
+	// Then
+	assert(err).IsNil()
+	assert(w.Header()["grpc-status"]).Equals([]string{"3"})
+	assert(w.Header()["grpc-message"]).Equals([]string{"dummy-msg 100%25 %C3%BC%0A"})
+	assert(len(w.Header()["grpc-status-details-bin"])).Equals(1)
+}
+
+func TestStatusFromHeaders(t *testing.T) {
+	errorInfo := ErrorInfo{Reason: "dummy-reason", Domain: "dummy-domain"}
+	gRPCErr := New(codes.Unavailable).
+		Message("dummy-msg 100% ü").
+		ErrorInfo(&errorInfo).
+		RequestInfo(&RequestInfo{RequestID: "dummy-request-id"}).
+		RetryInfo(&RetryInfo{RetryDelay: 1500 * time.Millisecond}).
+		Err()
+
+	tests := []struct {
+		name          string
+		header        http.Header
+		wantErr       bool
+		wantCode      codes.Code
+		wantMsg       string
+		wantErrorInfo ErrorInfo
+		wantRetryInfo RetryInfo
+		wantRequestID string
+	}{
+		{
+			name:          "Should return status held by gRPC status details header",
+			header:        recordedHeader(t, gRPCErr, WithGRPCStatusHeaders()),
+			wantCode:      codes.Unavailable,
+			wantMsg:       "dummy-msg 100% ü",
+			wantErrorInfo: errorInfo,
+			wantRetryInfo: RetryInfo{RetryDelay: 1500 * time.Millisecond},
+			wantRequestID: "dummy-request-id",
+		},
+		{
+			name:     "Should return status held by gRPC status and message headers",
+			header:   http.Header{"Grpc-Status": []string{"5"}, "Grpc-Message": []string{"dummy-msg 100%25 %C3%BC"}},
+			wantCode: codes.NotFound,
+			wantMsg:  "dummy-msg 100% ü",
+		},
+		{
+			name:          "Should return status held by error headers",
+			header:        recordedHeader(t, gRPCErr, WithErrorHeaders()),
+			wantCode:      codes.Unavailable,
+			wantMsg:       defaultUnavailableErrMsg,
+			wantErrorInfo: errorInfo,
+			wantRetryInfo: RetryInfo{RetryDelay: 1500 * time.Millisecond},
+			wantRequestID: "dummy-request-id",
+		},
+		{
+			name:    "Should return error when there are no error headers",
+			header:  http.Header{"Content-Type": []string{"application/json"}},
+			wantErr: true,
+		},
+		{
+			name:    "Should return error when gRPC status header isn't a number",
+			header:  http.Header{"Grpc-Status": []string{"dummy-status"}},
+			wantErr: true,
+		},
+		{
+			name:    "Should return error when gRPC status header is OK",
+			header:  http.Header{"Grpc-Status": []string{"0"}},
+			wantErr: true,
+		},
+		{
+			name:    "Should return error when gRPC status header isn't a gRPC code",
+			header:  http.Header{"Grpc-Status": []string{"404"}},
+			wantErr: true,
+		},
+		{
+			name:    "Should return error when gRPC status details header isn't base64",
+			header:  http.Header{"Grpc-Status-Details-Bin": []string{"!dummy!"}},
+			wantErr: true,
+		},
+		{
+			name:    "Should return error when error code header isn't a gRPC code",
+			header:  http.Header{"X-Error-Code": []string{"DUMMY_CODE"}},
+			wantErr: true,
+		},
+		{
+			name:    "Should return error when retry delay header isn't a duration",
+			header:  http.Header{"X-Error-Code": []string{"UNAVAILABLE"}, "X-Error-Retry-Delay": []string{"dummy-delay"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+
+			// When
+			st, err := StatusFromHeaders(tt.header)
+
+			// Then
+			if tt.wantErr {
+				assert(err).IsNotNil()
+				return
+			}
+			assert(err).IsNil()
+			got := st.Err()
+			assert(Code(got)).Equals(tt.wantCode)
+			assert(Message(got)).Equals(tt.wantMsg)
+			assert(ErrorInfoFrom(got)).Equals(tt.wantErrorInfo)
+			assert(RetryInfoFrom(got)).Equals(tt.wantRetryInfo)
+			assert(RequestInfoFrom(got).RequestID).Equals(tt.wantRequestID)
+		})
+	}
+}
+
+func TestFromHTTPResponseErrorHeaders(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+	resp := &http.Response{
+		StatusCode: http.StatusServiceUnavailable,
+		Status:     "503 Service Unavailable",
+		Header:     http.Header{"X-Error-Code": []string{"RESOURCE_EXHAUSTED"}, "X-Error-Reason": []string{"dummy-reason"}},
+		Body:       io.NopCloser(strings.NewReader("<html>dummy-body</html>")),
+	}
+
+	// When
+	got := FromHTTPResponse(resp)
+
+	// Then
+	assert(Code(got)).Equals(codes.ResourceExhausted)
+	assert(ErrorInfoFrom(got).Reason).Equals("dummy-reason")
+}
+
+// recordedHeader returns the headers of the HTTP response encoding the gRPC error with the option.
+func recordedHeader(t *testing.T, gRPCErr error, opt ResponseWriterOption) http.Header {
+	w := httptest.NewRecorder()
+	if err := NewHttpResponseEncodeWriter(w, opt)(gRPCErr).AsJSON(); err != nil {
+		t.Fatal(err)
+	}
+	return w.Result().Header
+}
@@ -1,67 +1,241 @@
 package grpcerr
 
 import (
-	"errors"
 	"fmt"
+	"html/template"
+	"math"
 	"net/http"
+	"strconv"
+	"sync/atomic"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 // ResponseWriterOption is an option function used to modify its http.ResponseWriter argument.
-// For example, to set additional header values or to modify existing ones.
+// For example, to set additional header values or to modify existing ones. The argument is a
+// *StatusResponseWriter, which gives access to the gRPC status being written, see WithStatusFunc.
 type ResponseWriterOption func(w http.ResponseWriter)
 
 type httpResponseEncoder struct {
-	gRPCErr error
-	w       http.ResponseWriter
-	opts    []ResponseWriterOption
+	gRPCErr      error
+	w            http.ResponseWriter
+	opts         []ResponseWriterOption
+	redaction    *RedactionPolicy
+	masking      *Masking
+	mapping      *HTTPStatusMapping
+	htmlTemplate *template.Template
+	jsonOptions  *JSONOptions
 }
 
-// AsJSON encodes the gRPC error as JSON and writes it to the http.ResponseWriter.
+// HTTPStatusMapping describes which HTTP status codes are sent for gRPC errors, overriding the default
+// mapping. For example, it allows sending 412 Precondition Failed for ETag mismatches or 422 Unprocessable
+// Entity for validation errors. The zero value overrides nothing.
+//
+// Example:
+//
+//	mapping := &grpcerr.HTTPStatusMapping{
+//	    Codes:   map[codes.Code]int{codes.InvalidArgument: http.StatusUnprocessableEntity},
+//	    Reasons: map[string]int{"ETAG_MISMATCH": http.StatusPreconditionFailed},
+//	}
+type HTTPStatusMapping struct {
+	// Codes holds the HTTP status codes sent per gRPC code.
+	Codes map[codes.Code]int
+	// Reasons holds the HTTP status codes sent per ErrorInfo reason. They take precedence over Codes.
+	Reasons map[string]int
+}
+
+// defaultHTTPStatusMapping holds the *HTTPStatusMapping set using SetDefaultHTTPStatusMapping.
+var defaultHTTPStatusMapping atomic.Value
+
+// SetDefaultHTTPStatusMapping sets the mapping used by the HTTP response encoder when no other mapping
+// is given. By default the HTTP status codes are only derived from the gRPC codes.
+func SetDefaultHTTPStatusMapping(mapping *HTTPStatusMapping) {
+	defaultHTTPStatusMapping.Store(mapping)
+}
+
+// DefaultHTTPStatusMapping returns the mapping set using SetDefaultHTTPStatusMapping, or nil if there isn't
+// any.
+func DefaultHTTPStatusMapping() *HTTPStatusMapping {
+	mapping, _ := defaultHTTPStatusMapping.Load().(*HTTPStatusMapping)
+	return mapping
+}
+
+// HTTPStatusCode returns the HTTP status code for the status. The ErrorInfo's reason is looked up first,
+// then the code. If neither is mapped, or if the mapping is nil, the default mapping is used.
+func (m *HTTPStatusMapping) HTTPStatusCode(st *status.Status) int {
+	return m.httpStatusCode(st, httpStatusCodeFrom)
+}
+
+// httpStatusCode is like HTTPStatusCode, but it falls back to the given default mapping, which allows
+// encodings such as Twirp to have their own.
+func (m *HTTPStatusMapping) httpStatusCode(st *status.Status, defaultHTTPStatusCode func(st *status.Status) int) int {
+	if m == nil {
+		return defaultHTTPStatusCode(st)
+	}
+
+	if errorInfo, ok := errorInfoDetailsFrom(st); ok {
+		if httpStatusCode, ok := m.Reasons[errorInfo.Reason]; ok {
+			return httpStatusCode
+		}
+	}
+	if httpStatusCode, ok := m.Codes[st.Code()]; ok {
+		return httpStatusCode
+	}
+
+	return defaultHTTPStatusCode(st)
+}
+
+// MapStatus sets the mapping from gRPC errors to HTTP status codes, which overrides the default mapping
+// set using SetDefaultHTTPStatusMapping.
+func (f *httpResponseEncoder) MapStatus(mapping *HTTPStatusMapping) *httpResponseEncoder {
+	f.mapping = mapping
+	return f
+}
+
+// Mask sets how server faults are masked before they're encoded, which overrides the default masking
+// set using SetDefaultMasking.
+func (f *httpResponseEncoder) Mask(masking *Masking) *httpResponseEncoder {
+	f.masking = masking
+	return f
+}
+
+// Redact sets the redaction policy applied to the gRPC error before it's encoded, which overrides the
+// default policy set using SetDefaultRedactionPolicy. For example, internal callers can be allowed to see
+// everything by passing the zero value RedactionPolicy.
+func (f *httpResponseEncoder) Redact(policy *RedactionPolicy) *httpResponseEncoder {
+	f.redaction = policy
+	return f
+}
+
+// AsJSON encodes the gRPC error as JSON and writes it to the http.ResponseWriter. If the error isn't
+// a gRPC error, it's mapped to one using FromError.
 // If an error occurs it is returned, otherwise it returns nil.
 func (f *httpResponseEncoder) AsJSON() error {
-	if f.gRPCErr == nil {
+	return f.Encode(jsonEncoder{opts: f.jsonOptionsOrDefault()})
+}
+
+// AsProtobuf encodes the gRPC error as a binary google.rpc.Status protobuf message and writes it to the
+// http.ResponseWriter. If the error isn't a gRPC error, it's mapped to one using FromError.
+// If an error occurs it is returned, otherwise it returns nil.
+func (f *httpResponseEncoder) AsProtobuf() error {
+	return f.Encode(protobufEncoder{})
+}
+
+// As encodes the gRPC error using the encoder registered for the media type, see RegisterEncoder, and
+// writes it to the http.ResponseWriter. If the error isn't a gRPC error, it's mapped to one using FromError.
+// If an error occurs it is returned, otherwise it returns nil.
+func (f *httpResponseEncoder) As(mediaType string) error {
+	encoder, ok := f.encoderFor(mediaType)
+	if !ok {
 		f.w.WriteHeader(http.StatusInternalServerError)
 		f.w.Write(nil)
-		return fmt.Errorf("invalid argument: gRPCErr was nil")
+		return fmt.Errorf("invalid argument: no encoder registered for media type %q", mediaType)
 	}
 
-	rootErr := rootError(f.gRPCErr)
-	statusErr, ok := rootErr.(interface{ GRPCStatus() *status.Status })
-	if !ok {
+	return f.Encode(encoder)
+}
+
+// Auto encodes the gRPC error using the encoding preferred by the request's Accept header, taking its
+// q-values into account, and writes it to the http.ResponseWriter. The supported media types are the
+// ones registered using RegisterEncoder, which by default are application/json, application/x-protobuf,
+// application/problem+json, text/html and text/plain. If none of them is acceptable, JSON is used.
+// Since the response depends on the Accept header, the Vary header is set to Accept.
+// If an error occurs it is returned, otherwise it returns nil.
+func (f *httpResponseEncoder) Auto(r *http.Request) error {
+	f.w.Header().Add("Vary", "Accept")
+
+	var accept string
+	if r != nil {
+		accept = r.Header.Get("Accept")
+	}
+
+	mediaType := negotiateMediaType(accept, registeredMediaTypes())
+	if mediaType == "" {
+		return f.AsJSON()
+	}
+
+	return f.As(mediaType)
+}
+
+// Encode encodes the gRPC error using the encoder and writes it to the http.ResponseWriter. It allows
+// using encoders which aren't registered. The gRPC error is mapped, masked and redacted before it's
+// encoded. If the error isn't a gRPC error, it's mapped to one using FromError.
+// If an error occurs it is returned, otherwise it returns nil.
+func (f *httpResponseEncoder) Encode(encoder Encoder) error {
+	if f.gRPCErr == nil {
 		f.w.WriteHeader(http.StatusInternalServerError)
 		f.w.Write(nil)
-		return fmt.Errorf("invalid argument: gRPCErr's root error must have the GRPCStatus() method")
+		return fmt.Errorf("invalid argument: gRPCErr was nil")
 	}
 
-	st := statusErr.GRPCStatus()
+	st := clientStatusFrom("http", f.gRPCErr, f.maskingOrDefault(), f.redactionPolicy())
+
+	defaultHTTPStatusCode := httpStatusCodeFrom
+	if coder, ok := encoder.(defaultHTTPStatusCoder); ok {
+		defaultHTTPStatusCode = coder.defaultHTTPStatusCode
+	}
+	httpStatusCode := f.httpStatusMapping().httpStatusCode(st, defaultHTTPStatusCode)
 
-	json, err := jsonBytesFromGrpcStatus(st)
+	header := http.Header{}
+	body, err := encoder.Encode(st, httpStatusCode, header)
 	if err != nil {
 		f.w.WriteHeader(http.StatusInternalServerError)
 		f.w.Write(nil)
-		return fmt.Errorf("could not get JSON as bytes from gRPC status: %w", err)
+		return err
 	}
 
 	// Sets sane defaults
-	f.w.Header().Set("Content-Type", "application/json")
+	f.w.Header().Set("Content-Type", encoder.ContentType())
+	if retryAfter, ok := retryAfterFrom(st); ok {
+		f.w.Header().Set("Retry-After", retryAfter)
+	}
+	for k, v := range header {
+		f.w.Header()[k] = v
+	}
 
 	// Sets the passed options, which must be set between the Content-Type assignment and f.w.WriteHeader().
 	// Otherwhise it's not possible to change the Content-Type header using the below options.
+	sw := &StatusResponseWriter{ResponseWriter: f.w, Status: st, StatusCode: httpStatusCode}
 	for _, opt := range f.opts {
-		opt(f.w)
+		opt(sw)
 	}
 
 	// Sets sane defaults
-	f.w.WriteHeader(httpStatusCodeFrom(st))
+	f.w.WriteHeader(sw.StatusCode)
 
-	f.w.Write(json)
+	f.w.Write(body)
 
 	return nil
 }
 
+// clientStatusFrom returns the status of err as it's sent to clients. Errors that aren't gRPC errors are
+// mapped using the registered mappers, then server faults are masked, the error is recorded as coming from
+// source and the status is redacted.
+func clientStatusFrom(source string, err error, masking *Masking, policy *RedactionPolicy) *status.Status {
+	gRPCErr := FromError(err)
+	st, masked := masking.mask(gRPCErr)
+	recordRecentError(source, gRPCErr, masked)
+
+	return policy.Redact(st)
+}
+
+// encoderFor returns the encoder registered for the media type. The built-in JSON and HTML encoders are
+// replaced by ones using the options set using JSONOptions() and the template set using HTMLTemplate().
+func (f *httpResponseEncoder) encoderFor(mediaType string) (Encoder, bool) {
+	encoder, ok := registeredEncoder(mediaType)
+	switch encoder.(type) {
+	case jsonEncoder:
+		encoder = jsonEncoder{opts: f.jsonOptionsOrDefault()}
+	case htmlEncoder:
+		if f.htmlTemplate != nil {
+			encoder = htmlEncoder{tmpl: f.htmlTemplate}
+		}
+	}
+
+	return encoder, ok
+}
+
 // NewHttpResponseEncodeWriter returns a function which is used to write a gRPC error to a http.ResponseWriter
 // using an encoding such as JSON.
 func NewHttpResponseEncodeWriter(w http.ResponseWriter, opts ...ResponseWriterOption) func(error) *httpResponseEncoder {
@@ -74,6 +248,30 @@ func NewHttpResponseEncodeWriter(w http.ResponseWriter, opts ...ResponseWriterOp
 	}
 }
 
+// redactionPolicy returns the redaction policy set using Redact(), or the default one if there isn't any.
+func (f *httpResponseEncoder) redactionPolicy() *RedactionPolicy {
+	if f.redaction != nil {
+		return f.redaction
+	}
+	return DefaultRedactionPolicy()
+}
+
+// maskingOrDefault returns the masking set using Mask(), or the default one if there isn't any.
+func (f *httpResponseEncoder) maskingOrDefault() *Masking {
+	if f.masking != nil {
+		return f.masking
+	}
+	return DefaultMasking()
+}
+
+// httpStatusMapping returns the mapping set using MapStatus(), or the default one if there isn't any.
+func (f *httpResponseEncoder) httpStatusMapping() *HTTPStatusMapping {
+	if f.mapping != nil {
+		return f.mapping
+	}
+	return DefaultHTTPStatusMapping()
+}
+
 func httpStatusCodeFrom(st *status.Status) int {
 	switch st.Code() {
 	case codes.Aborted, codes.AlreadyExists:
@@ -104,12 +302,18 @@ func httpStatusCodeFrom(st *status.Status) int {
 	return http.StatusInternalServerError
 }
 
-// rootError recursively unwraps errors until the root error is found and then returns it.
-func rootError(err error) error {
-	unwrappedErr := errors.Unwrap(err)
-	if unwrappedErr == nil {
-		return err
+// retryAfterFrom returns the value of the Retry-After HTTP header, which is the RetryInfo's delay in
+// seconds rounded up. The boolean is false if the status does not hold any RetryInfo.
+func retryAfterFrom(st *status.Status) (string, bool) {
+	retryInfo, ok := retryInfoDetailsFrom(st)
+	if !ok {
+		return "", false
 	}
 
-	return rootError(unwrappedErr)
+	delay := retryInfo.RetryDelay.AsDuration()
+	if delay < 0 {
+		delay = 0
+	}
+	seconds := int64(math.Ceil(delay.Seconds()))
+	return strconv.FormatInt(seconds, 10), true
 }
@@ -1,6 +1,7 @@
 package grpcerr
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -8,10 +9,13 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/tobbstr/testa/assert"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestHttpResponseEncodeWriteAsJSON(t *testing.T) {
@@ -398,7 +402,7 @@ func TestHttpResponseEncodeWriteAsJSON(t *testing.T) {
 			wantErr: nil,
 		},
 		{
-			name: "Should return error when gRPCErr does not have GRPCStatus() method",
+			name: "Should write Unknown gRPC error when gRPCErr does not have GRPCStatus() method and is not mapped",
 			args: args{
 				w:       httptest.NewRecorder(),
 				opts:    nil,
@@ -406,10 +410,28 @@ func TestHttpResponseEncodeWriteAsJSON(t *testing.T) {
 			},
 			want: &http.Response{
 				StatusCode: http.StatusInternalServerError,
-				Body:       io.NopCloser(strings.NewReader("")),
-				Header:     map[string][]string{},
+				Body:       io.NopCloser(strings.NewReader(`{"code":2, "message":"Unknown server error. Typically a server bug."}`)),
+				Header: map[string][]string{
+					"Content-Type": {"application/json"},
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Should write mapped gRPC error when gRPCErr does not have GRPCStatus() method and is mapped",
+			args: args{
+				w:       httptest.NewRecorder(),
+				opts:    nil,
+				gRPCErr: fmt.Errorf("dummy-wrapping-error: %w", context.DeadlineExceeded),
+			},
+			want: &http.Response{
+				StatusCode: http.StatusGatewayTimeout,
+				Body:       io.NopCloser(strings.NewReader(`{"code":4, "message":"` + defaultDeadlineExceededErrMsg + `"}`)),
+				Header: map[string][]string{
+					"Content-Type": {"application/json"},
+				},
 			},
-			wantErr: fmt.Errorf("invalid argument: gRPCErr's root error must have the GRPCStatus() method"),
+			wantErr: nil,
 		},
 		{
 			name: "Should write correct HTTP response when gRPCErr is wrapped error",
@@ -489,3 +511,232 @@ func Test_httpStatusCodeFrom(t *testing.T) {
 		})
 	}
 }
+
+func TestHttpResponseEncodeWriteAsJSONRetryAfter(t *testing.T) {
+	unavailable, err := NewUnavailable("dummy-msg", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unavailableWithRetryInfo, err := AddRetryInfo(unavailable, &RetryInfo{RetryDelay: 1500 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		gRPCErr error
+		want    string
+	}{
+		{
+			name:    "Should set Retry-After header rounded up to whole seconds when get gRPC error with RetryInfo",
+			gRPCErr: unavailableWithRetryInfo,
+			want:    "2",
+		},
+		{
+			name:    "Should not set Retry-After header when get gRPC error without RetryInfo",
+			gRPCErr: unavailable,
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			w := httptest.NewRecorder()
+			encodeAndWrite := NewHttpResponseEncodeWriter(w)
+
+			// When
+			err := encodeAndWrite(tt.gRPCErr).AsJSON()
+
+			// Then
+			assert(err).IsNil()
+			assert(w.Result().Header.Get("Retry-After")).Equals(tt.want)
+		})
+	}
+}
+
+func TestHttpResponseEncodeWriteAsJSONRedaction(t *testing.T) {
+	defer SetDefaultRedactionPolicy(nil)
+
+	internal, err := NewInternal("dummy-msg", &DebugInfo{Detail: "dummy-detail"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		defaultPolicy *RedactionPolicy
+		policy        *RedactionPolicy
+		want          string
+	}{
+		{
+			name:          "Should not redact when there is no policy",
+			defaultPolicy: nil,
+			policy:        nil,
+			want:          `{"code":13, "message":"dummy-msg", "details":[{"@type":"type.googleapis.com/google.rpc.DebugInfo", "detail":"dummy-detail"}]}`,
+		},
+		{
+			name:          "Should redact according to default policy",
+			defaultPolicy: ExternalRedactionPolicy,
+			policy:        nil,
+			want:          `{"code":13, "message":"dummy-msg"}`,
+		},
+		{
+			name:          "Should redact according to given policy which overrides default policy",
+			defaultPolicy: ExternalRedactionPolicy,
+			policy:        &RedactionPolicy{},
+			want:          `{"code":13, "message":"dummy-msg", "details":[{"@type":"type.googleapis.com/google.rpc.DebugInfo", "detail":"dummy-detail"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			SetDefaultRedactionPolicy(tt.defaultPolicy)
+			w := httptest.NewRecorder()
+			encodeAndWrite := NewHttpResponseEncodeWriter(w)
+
+			// When
+			err := encodeAndWrite(internal).Redact(tt.policy).AsJSON()
+
+			// Then
+			assert(err).IsNil()
+			assert(w.Body.String()).IsJSONEqualTo(tt.want)
+		})
+	}
+}
+
+func TestHttpResponseEncodeWriteAsJSONHTTPStatusMapping(t *testing.T) {
+	defer SetDefaultHTTPStatusMapping(nil)
+
+	etagMismatch := New(codes.FailedPrecondition).ErrorInfo(&ErrorInfo{Reason: "ETAG_MISMATCH", Domain: "dummy-domain"}).Err()
+	invalidArgument := New(codes.InvalidArgument).Err()
+	mapping := &HTTPStatusMapping{
+		Codes:   map[codes.Code]int{codes.InvalidArgument: http.StatusUnprocessableEntity, codes.FailedPrecondition: http.StatusConflict},
+		Reasons: map[string]int{"ETAG_MISMATCH": http.StatusPreconditionFailed},
+	}
+
+	tests := []struct {
+		name           string
+		defaultMapping *HTTPStatusMapping
+		mapping        *HTTPStatusMapping
+		gRPCErr        error
+		want           int
+	}{
+		{
+			name:           "Should use default mapping when there is no mapping",
+			defaultMapping: nil,
+			mapping:        nil,
+			gRPCErr:        etagMismatch,
+			want:           http.StatusBadRequest,
+		},
+		{
+			name:           "Should map ErrorInfo reason before code",
+			defaultMapping: nil,
+			mapping:        mapping,
+			gRPCErr:        etagMismatch,
+			want:           http.StatusPreconditionFailed,
+		},
+		{
+			name:           "Should map code when reason is not mapped",
+			defaultMapping: nil,
+			mapping:        mapping,
+			gRPCErr:        invalidArgument,
+			want:           http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Should use default mapping when neither reason nor code is mapped",
+			defaultMapping: nil,
+			mapping:        mapping,
+			gRPCErr:        New(codes.NotFound).Err(),
+			want:           http.StatusNotFound,
+		},
+		{
+			name:           "Should map according to default mapping",
+			defaultMapping: mapping,
+			mapping:        nil,
+			gRPCErr:        invalidArgument,
+			want:           http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Should map according to given mapping which overrides default mapping",
+			defaultMapping: mapping,
+			mapping:        &HTTPStatusMapping{},
+			gRPCErr:        invalidArgument,
+			want:           http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			SetDefaultHTTPStatusMapping(tt.defaultMapping)
+			w := httptest.NewRecorder()
+			encodeAndWrite := NewHttpResponseEncodeWriter(w)
+
+			// When
+			err := encodeAndWrite(tt.gRPCErr).MapStatus(tt.mapping).AsJSON()
+
+			// Then
+			assert(err).IsNil()
+			assert(w.Code).Equals(tt.want)
+		})
+	}
+}
+
+func TestHttpResponseEncodeWriteAsProtobuf(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+	w := httptest.NewRecorder()
+	gRPCErr := New(codes.NotFound).Message("dummy-msg").ResourceInfo(&ResourceInfo{ResourceName: "dummy-resource-name"}).Err()
+
+	// When
+	err := NewHttpResponseEncodeWriter(w)(gRPCErr).AsProtobuf()
+
+	// Then
+	assert(err).IsNil()
+	assert(w.Code).Equals(http.StatusNotFound)
+	assert(w.Header().Get("Content-Type")).Equals("application/x-protobuf")
+	var st spb.Status
+	assert(proto.Unmarshal(w.Body.Bytes(), &st)).IsNil()
+	got := status.FromProto(&st).Err()
+	assert(Code(got)).Equals(codes.NotFound)
+	assert(Message(got)).Equals("dummy-msg")
+	assert(ResourceInfoFrom(got)).Equals(ResourceInfo{ResourceName: "dummy-resource-name"})
+}
+
+func TestHttpResponseEncodeWriteAuto(t *testing.T) {
+	tests := []struct {
+		name            string
+		accept          string
+		wantContentType string
+	}{
+		{name: "Should encode as JSON when there is no Accept header", accept: "", wantContentType: "application/json"},
+		{name: "Should encode as protobuf when it's preferred", accept: "application/json;q=0.5, application/x-protobuf", wantContentType: "application/x-protobuf"},
+		{name: "Should encode as problem JSON when it's preferred", accept: "application/problem+json", wantContentType: "application/problem+json"},
+		{name: "Should encode as JSON when it's preferred", accept: "application/x-protobuf;q=0.5, application/json", wantContentType: "application/json"},
+		{name: "Should encode as HTML when get Accept header of browser", accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", wantContentType: "text/html; charset=utf-8"},
+		{name: "Should encode as text when it's preferred", accept: "text/plain", wantContentType: "text/plain; charset=utf-8"},
+		{name: "Should encode as JSON when get any media type", accept: "*/*", wantContentType: "application/json"},
+		{name: "Should fall back to JSON when no encoding is acceptable", accept: "image/png", wantContentType: "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept", tt.accept)
+
+			// When
+			err := NewHttpResponseEncodeWriter(w)(New(codes.NotFound).Err()).Auto(r)
+
+			// Then
+			assert(err).IsNil()
+			assert(w.Code).Equals(http.StatusNotFound)
+			assert(w.Header().Get("Content-Type")).Equals(tt.wantContentType)
+			assert(w.Header().Get("Vary")).Equals("Accept")
+			assert(Code(FromHTTPResponse(w.Result()))).Equals(codes.NotFound)
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package grpcerr
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Incident is a server fault whose message and details were masked before the error was sent to
+// the client. Support can look it up using the incident ID the client reports.
+type Incident struct {
+	// The incident ID, which is also sent to the client as the RequestInfo's request ID.
+	ID string
+	// When the incident occurred.
+	Time time.Time
+	// The original gRPC status, including its message and details.
+	Status *status.Status
+	// The original error.
+	Err error
+}
+
+// IncidentSink records incidents. It must be safe for concurrent use.
+type IncidentSink interface {
+	Record(incident Incident)
+}
+
+// IncidentSinkFunc is an adapter which allows an ordinary function to be used as an IncidentSink.
+type IncidentSinkFunc func(incident Incident)
+
+// Record calls f(incident).
+func (f IncidentSinkFunc) Record(incident Incident) {
+	f(incident)
+}
+
+// NewLogSink returns an IncidentSink which logs incidents to logger, including the original details
+// and cause. If logger is nil, the standard logger is used.
+func NewLogSink(logger *log.Logger) IncidentSink {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return IncidentSinkFunc(func(incident Incident) {
+		logger.Printf("incident %s: %+v", incident.ID, &Error{st: incident.Status, cause: errorsCause(incident.Err)})
+	})
+}
+
+// errorsCause returns err unless it's an *Error, in which case its cause is returned, so that the
+// gRPC status isn't printed twice.
+func errorsCause(err error) error {
+	if e, ok := err.(*Error); ok {
+		return e.cause
+	}
+	return err
+}
+
+// IncidentRingBuffer is an IncidentSink which keeps the most recent incidents in memory.
+type IncidentRingBuffer struct {
+	ring *ring
+}
+
+// NewIncidentRingBuffer returns an IncidentRingBuffer which keeps the given number of most recent incidents.
+func NewIncidentRingBuffer(size int) *IncidentRingBuffer {
+	return &IncidentRingBuffer{ring: newRing(size)}
+}
+
+// Record stores the incident, replacing the oldest one if the buffer is full.
+func (b *IncidentRingBuffer) Record(incident Incident) {
+	b.ring.add(incident)
+}
+
+// Lookup returns the incident with the ID. The boolean is false if there isn't any, for example
+// because it has been replaced by more recent incidents.
+func (b *IncidentRingBuffer) Lookup(id string) (Incident, bool) {
+	for _, incident := range b.Incidents() {
+		if incident.ID == id {
+			return incident, true
+		}
+	}
+
+	return Incident{}, false
+}
+
+// Incidents returns the incidents in the buffer, the most recent first.
+func (b *IncidentRingBuffer) Incidents() []Incident {
+	items := b.ring.items()
+	incidents := make([]Incident, 0, len(items))
+	for _, item := range items {
+		incidents = append(incidents, item.(Incident))
+	}
+
+	return incidents
+}
+
+// Masking describes how server faults are masked. Their public message is replaced by the default
+// message of their code and all their details are replaced by a RequestInfo holding a generated incident
+// ID. The original error is recorded in the sink, together with the incident ID.
+type Masking struct {
+	// The codes of the errors which are masked. If empty, Internal, Unknown and DataLoss errors are masked.
+	Codes []codes.Code
+	// Where the original errors are recorded. If nil, they're discarded.
+	Sink IncidentSink
+	// Generates incident IDs. If nil, random 128-bit hex encoded IDs are generated.
+	NewIncidentID func() string
+}
+
+// defaultMasking holds the *Masking set using SetDefaultMasking.
+var defaultMasking atomic.Value
+
+// SetDefaultMasking sets the masking used by the HTTP response encoder and the server interceptors when
+// no other masking is given. By default nothing is masked.
+func SetDefaultMasking(masking *Masking) {
+	defaultMasking.Store(masking)
+}
+
+// DefaultMasking returns the masking set using SetDefaultMasking, or nil if there isn't any.
+func DefaultMasking() *Masking {
+	masking, _ := defaultMasking.Load().(*Masking)
+	return masking
+}
+
+// MaskError returns the masked gRPC error if gRPCErr is a server fault, otherwise gRPCErr is returned.
+// The cause of the masked gRPC error is gRPCErr. If the masking is nil, gRPCErr is returned.
+func (m *Masking) MaskError(gRPCErr error) error {
+	_, masked := m.mask(gRPCErr)
+	return masked
+}
+
+// mask masks gRPCErr like MaskError does, and also returns the status sent to clients. If gRPCErr is
+// masked, it's the status of the masked error itself. It mustn't be looked up in the masked error using
+// the lookup policy, since LookupInnermost would find the status of its cause, which is gRPCErr.
+func (m *Masking) mask(gRPCErr error) (*status.Status, error) {
+	st := convert(gRPCErr)
+	if m == nil || gRPCErr == nil || !m.masks(st.Code()) {
+		return st, gRPCErr
+	}
+
+	newIncidentID := m.NewIncidentID
+	if newIncidentID == nil {
+		newIncidentID = randomIncidentID
+	}
+	incident := Incident{
+		ID:     newIncidentID(),
+		Time:   time.Now(),
+		Status: st,
+		Err:    gRPCErr,
+	}
+	if m.Sink != nil {
+		m.Sink.Record(incident)
+	}
+
+	masked := New(st.Code()).
+		RequestInfo(&RequestInfo{RequestID: incident.ID}).
+		Cause(gRPCErr).
+		errWithoutStack()
+
+	return masked.(*Error).st, masked
+}
+
+func (m *Masking) masks(code codes.Code) bool {
+	if len(m.Codes) == 0 {
+		return code == codes.Internal || code == codes.Unknown || code == codes.DataLoss
+	}
+
+	for _, c := range m.Codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func randomIncidentID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().UTC().Format("20060102T150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,223 @@
+package grpcerr
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tobbstr/testa/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestMaskingMaskError(t *testing.T) {
+	internal := New(codes.Internal).
+		Message("dummy-msg: could not connect to db.internal:5432").
+		DebugInfo(&DebugInfo{Detail: "dummy-detail"}).
+		Err()
+	notFound := New(codes.NotFound).Message("dummy-msg").Err()
+	newIncidentID := func() string { return "dummy-incident-id" }
+
+	type args struct {
+		masking *Masking
+		gRPCErr error
+	}
+	tests := []struct {
+		name            string
+		args            args
+		wantMsg         string
+		wantRequestInfo RequestInfo
+		wantIncidents   int
+	}{
+		{
+			name: "should mask Internal error when get default codes",
+			args: args{
+				masking: &Masking{NewIncidentID: newIncidentID},
+				gRPCErr: internal,
+			},
+			wantMsg:         defaultInternalErrMsg,
+			wantRequestInfo: RequestInfo{RequestID: "dummy-incident-id"},
+			wantIncidents:   1,
+		},
+		{
+			name: "should not mask NotFound error when get default codes",
+			args: args{
+				masking: &Masking{NewIncidentID: newIncidentID},
+				gRPCErr: notFound,
+			},
+			wantMsg:         "dummy-msg",
+			wantRequestInfo: RequestInfo{},
+			wantIncidents:   0,
+		},
+		{
+			name: "should mask NotFound error when get NotFound code",
+			args: args{
+				masking: &Masking{Codes: []codes.Code{codes.NotFound}, NewIncidentID: newIncidentID},
+				gRPCErr: notFound,
+			},
+			wantMsg:         defaultNotFoundErrMsg,
+			wantRequestInfo: RequestInfo{RequestID: "dummy-incident-id"},
+			wantIncidents:   1,
+		},
+		{
+			name: "should not mask error when get nil masking",
+			args: args{
+				masking: nil,
+				gRPCErr: internal,
+			},
+			wantMsg:         "dummy-msg: could not connect to db.internal:5432",
+			wantRequestInfo: RequestInfo{},
+			wantIncidents:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			sink := NewIncidentRingBuffer(10)
+			if tt.args.masking != nil {
+				tt.args.masking.Sink = sink
+			}
+
+			// When
+			got := tt.args.masking.MaskError(tt.args.gRPCErr)
+
+			// Then
+			assert(Code(got)).Equals(Code(tt.args.gRPCErr))
+			assert(Message(got)).Equals(tt.wantMsg)
+			assert(RequestInfoFrom(got)).Equals(tt.wantRequestInfo)
+			assert(len(sink.Incidents())).Equals(tt.wantIncidents)
+			if tt.wantIncidents > 0 {
+				assert(DebugInfoFrom(got)).Equals(DebugInfo{})
+				incident, ok := sink.Lookup("dummy-incident-id")
+				assert(ok).IsTrue()
+				assert(incident.Status.Message()).Equals(Message(tt.args.gRPCErr))
+				assert(incident.Err).Equals(tt.args.gRPCErr)
+			}
+		})
+	}
+}
+
+func TestMaskingMaskErrorGeneratesUniqueIncidentIDs(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+	masking := &Masking{}
+	internal := New(codes.Internal).Err()
+
+	// When
+	first := RequestInfoFrom(masking.MaskError(internal)).RequestID
+	second := RequestInfoFrom(masking.MaskError(internal)).RequestID
+
+	// Then
+	assert(len(first)).Equals(32)
+	assert(first).NotEquals(second)
+}
+
+func TestIncidentRingBuffer(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		records int
+		wantIDs []string
+	}{
+		{
+			name:    "should return no incidents when empty",
+			size:    3,
+			records: 0,
+			wantIDs: []string{},
+		},
+		{
+			name:    "should return incidents most recent first when not full",
+			size:    3,
+			records: 2,
+			wantIDs: []string{"1", "0"},
+		},
+		{
+			name:    "should replace oldest incidents when full",
+			size:    3,
+			records: 5,
+			wantIDs: []string{"4", "3", "2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			buffer := NewIncidentRingBuffer(tt.size)
+
+			// When
+			for i := 0; i < tt.records; i++ {
+				buffer.Record(Incident{ID: strconv.Itoa(i)})
+			}
+
+			// Then
+			gotIDs := []string{}
+			for _, incident := range buffer.Incidents() {
+				gotIDs = append(gotIDs, incident.ID)
+			}
+			assert(gotIDs).Equals(tt.wantIDs)
+			_, ok := buffer.Lookup("0")
+			assert(ok).Equals(tt.records > 0 && tt.records <= tt.size)
+		})
+	}
+}
+
+func TestNewLogSink(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+	var buf bytes.Buffer
+	sink := NewLogSink(log.New(&buf, "", 0))
+	gRPCErr := New(codes.Internal).Message("dummy-msg").Cause(errors.New("dummy-cause")).Err()
+
+	// When
+	sink.Record(Incident{ID: "dummy-incident-id", Status: convert(gRPCErr), Err: gRPCErr})
+
+	// Then
+	assert(buf.String()).Equals("incident dummy-incident-id: rpc error: code = Internal desc = dummy-msg\ncaused by: dummy-cause\n")
+}
+
+func TestHttpResponseEncodeWriteAsJSONMasking(t *testing.T) {
+	defer SetLookupPolicy(LookupOutermost)
+
+	tests := []struct {
+		name   string
+		policy LookupPolicy
+	}{
+		{
+			name:   "should mask error when lookup policy is outermost",
+			policy: LookupOutermost,
+		},
+		{
+			name:   "should mask error when lookup policy is innermost",
+			policy: LookupInnermost,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			SetLookupPolicy(tt.policy)
+			sink := NewIncidentRingBuffer(1)
+			masking := &Masking{Sink: sink, NewIncidentID: func() string { return "dummy-incident-id" }}
+			w := httptest.NewRecorder()
+			internal := New(codes.Internal).
+				Message("dummy-msg: SELECT * FROM users").
+				DebugInfo(&DebugInfo{Detail: "dummy-detail"}).
+				Err()
+
+			// When
+			err := NewHttpResponseEncodeWriter(w)(internal).Mask(masking).AsJSON()
+
+			// Then
+			assert(err).IsNil()
+			assert(strings.Contains(w.Body.String(), "SELECT")).IsFalse()
+			assert(w.Body.String()).IsJSONEqualTo(`{"code":13, "message":"` + defaultInternalErrMsg + `", "details":[{"@type":"type.googleapis.com/google.rpc.RequestInfo", "requestId":"dummy-incident-id"}]}`)
+			incident, ok := sink.Lookup("dummy-incident-id")
+			assert(ok).IsTrue()
+			assert(incident.Status.Message()).Equals("dummy-msg: SELECT * FROM users")
+		})
+	}
+}
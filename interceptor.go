@@ -0,0 +1,95 @@
+package grpcerr
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// interceptorConfig holds the configuration of the server interceptors.
+type interceptorConfig struct {
+	redaction func(ctx context.Context) *RedactionPolicy
+	masking   *Masking
+}
+
+// InterceptorOption is an option function used to configure the server interceptors.
+type InterceptorOption func(c *interceptorConfig)
+
+// WithRedaction sets a function which selects the redaction policy applied to errors returned by
+// handlers, for example based on the caller's identity in ctx. If it returns nil, the default policy
+// set using SetDefaultRedactionPolicy is used.
+func WithRedaction(policyFor func(ctx context.Context) *RedactionPolicy) InterceptorOption {
+	return func(c *interceptorConfig) {
+		c.redaction = policyFor
+	}
+}
+
+// WithMasking sets how server faults returned by handlers are masked, which overrides the default
+// masking set using SetDefaultMasking.
+func WithMasking(masking *Masking) InterceptorOption {
+	return func(c *interceptorConfig) {
+		c.masking = masking
+	}
+}
+
+func newInterceptorConfig(opts []InterceptorOption) *interceptorConfig {
+	c := &interceptorConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// handleErr maps err to a gRPC error, masks it if it's a server fault, records it and redacts it.
+func (c *interceptorConfig) handleErr(ctx context.Context, fullMethod string, err error) error {
+	masking := c.masking
+	if masking == nil {
+		masking = DefaultMasking()
+	}
+	gRPCErr := FromError(err)
+	st, masked := masking.mask(gRPCErr)
+	recordRecentError(fullMethod, gRPCErr, masked)
+
+	var policy *RedactionPolicy
+	if c.redaction != nil {
+		policy = c.redaction(ctx)
+	}
+	if policy == nil {
+		policy = DefaultRedactionPolicy()
+	}
+	if policy == nil {
+		return masked
+	}
+
+	// Unlike RedactError, which redacts the outermost status, this redacts the status sent to clients,
+	// which honours the lookup policy unless the error was masked
+	return &Error{st: policy.Redact(st)}
+}
+
+// UnaryServerInterceptor returns a gRPC server interceptor which maps errors returned by unary
+// handlers to gRPC errors using FromError. For example, a handler returning context.Canceled results
+// in a Canceled gRPC error instead of an Unknown one. Server faults are then masked and all errors
+// are redacted according to the redaction policy.
+func UnaryServerInterceptor(opts ...InterceptorOption) grpc.UnaryServerInterceptor {
+	c := newInterceptorConfig(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, c.handleErr(ctx, info.FullMethod, err)
+		}
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor returns a gRPC server interceptor which maps errors returned by streaming
+// handlers to gRPC errors using FromError. Server faults are then masked and all errors are redacted
+// according to the redaction policy.
+func StreamServerInterceptor(opts ...InterceptorOption) grpc.StreamServerInterceptor {
+	c := newInterceptorConfig(opts)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return c.handleErr(ss.Context(), info.FullMethod, err)
+		}
+		return nil
+	}
+}
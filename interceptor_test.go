@@ -0,0 +1,194 @@
+package grpcerr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tobbstr/testa/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// dummyServerStream is a grpc.ServerStream which only implements the Context() method.
+type dummyServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *dummyServerStream) Context() context.Context { return s.ctx }
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{
+			name:     "should return nil when handler returns nil",
+			err:      nil,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "should map error when handler returns error which is not a gRPC error",
+			err:      context.Canceled,
+			wantCode: codes.Canceled,
+		},
+		{
+			name:     "should return gRPC error when handler returns gRPC error",
+			err:      New(codes.NotFound).Err(),
+			wantCode: codes.NotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			interceptor := UnaryServerInterceptor()
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, tt.err
+			}
+
+			// When
+			_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+
+			// Then
+			assert(Code(err)).Equals(tt.wantCode)
+		})
+	}
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{
+			name:     "should return nil when handler returns nil",
+			err:      nil,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "should map error when handler returns error which is not a gRPC error",
+			err:      context.DeadlineExceeded,
+			wantCode: codes.DeadlineExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			interceptor := StreamServerInterceptor()
+			handler := func(srv interface{}, ss grpc.ServerStream) error {
+				return tt.err
+			}
+
+			// When
+			err := interceptor(nil, &dummyServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, handler)
+
+			// Then
+			assert(Code(err)).Equals(tt.wantCode)
+		})
+	}
+}
+
+func TestUnaryServerInterceptorWithRedaction(t *testing.T) {
+	defer SetDefaultRedactionPolicy(nil)
+
+	type internalCallerKey struct{}
+	internalCaller := context.WithValue(context.Background(), internalCallerKey{}, true)
+	policyFor := func(ctx context.Context) *RedactionPolicy {
+		if ctx.Value(internalCallerKey{}) != nil {
+			return &RedactionPolicy{}
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		opts          []InterceptorOption
+		wantDebugInfo DebugInfo
+	}{
+		{
+			name:          "should redact error according to default policy",
+			ctx:           context.Background(),
+			opts:          nil,
+			wantDebugInfo: DebugInfo{},
+		},
+		{
+			name:          "should redact error according to default policy when selected policy is nil",
+			ctx:           context.Background(),
+			opts:          []InterceptorOption{WithRedaction(policyFor)},
+			wantDebugInfo: DebugInfo{},
+		},
+		{
+			name:          "should redact error according to selected policy",
+			ctx:           internalCaller,
+			opts:          []InterceptorOption{WithRedaction(policyFor)},
+			wantDebugInfo: DebugInfo{Detail: "dummy-detail"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			SetDefaultRedactionPolicy(ExternalRedactionPolicy)
+			interceptor := UnaryServerInterceptor(tt.opts...)
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, New(codes.Internal).DebugInfo(&DebugInfo{Detail: "dummy-detail"}).Err()
+			}
+
+			// When
+			_, err := interceptor(tt.ctx, nil, &grpc.UnaryServerInfo{}, handler)
+
+			// Then
+			assert(Code(err)).Equals(codes.Internal)
+			assert(DebugInfoFrom(err)).Equals(tt.wantDebugInfo)
+		})
+	}
+}
+
+func TestUnaryServerInterceptorWithMasking(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+	sink := NewIncidentRingBuffer(1)
+	interceptor := UnaryServerInterceptor(WithMasking(&Masking{Sink: sink}))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, New(codes.Internal).Message("dummy-msg: could not connect to db.internal:5432").Err()
+	}
+
+	// When
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+
+	// Then
+	assert(Code(err)).Equals(codes.Internal)
+	assert(Message(err)).Equals(defaultInternalErrMsg)
+	incident, ok := sink.Lookup(RequestInfoFrom(err).RequestID)
+	assert(ok).IsTrue()
+	assert(incident.Status.Message()).Equals("dummy-msg: could not connect to db.internal:5432")
+}
+
+func TestUnaryServerInterceptorWithMaskingAndRedaction(t *testing.T) {
+	defer SetLookupPolicy(LookupOutermost)
+
+	// Given
+	assert := assert.New(t)
+	SetLookupPolicy(LookupInnermost)
+	interceptor := UnaryServerInterceptor(
+		WithMasking(&Masking{NewIncidentID: func() string { return "dummy-incident-id" }}),
+		WithRedaction(func(ctx context.Context) *RedactionPolicy { return ExternalRedactionPolicy }),
+	)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, New(codes.Internal).Message("dummy-msg: SELECT * FROM secrets").DebugInfo(&DebugInfo{Detail: "dummy-detail"}).Err()
+	}
+
+	// When
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+
+	// Then
+	assert(Code(err)).Equals(codes.Internal)
+	assert(Message(err)).Equals(defaultInternalErrMsg)
+	assert(DebugInfoFrom(err)).Equals(DebugInfo{})
+	assert(RequestInfoFrom(err).RequestID).Equals("dummy-incident-id")
+}
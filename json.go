@@ -0,0 +1,78 @@
+package grpcerr
+
+import (
+	"sync/atomic"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// JSONOptions describes how gRPC statuses are marshalled by AsJSON. The zero value marshals like
+// protojson.Marshal, which uses lowerCamelCase field names, leaves out unpopulated fields and doesn't indent.
+//
+// Example:
+//
+//	opts := &grpcerr.JSONOptions{UseProtoNames: true, Multiline: true}
+type JSONOptions struct {
+	// UseProtoNames uses the field names of the proto files, for example "field_violations", rather than
+	// lowerCamelCase names, for example "fieldViolations".
+	UseProtoNames bool
+	// EmitUnpopulated emits unpopulated fields, for example empty messages and strings.
+	EmitUnpopulated bool
+	// Multiline spreads the JSON over multiple lines, which is meant for development.
+	Multiline bool
+	// Indent is the indentation used when Multiline is set. If it's empty, two spaces are used.
+	Indent string
+	// Resolver looks up the types of the details. It allows rendering custom detail messages which aren't
+	// registered in protoregistry.GlobalTypes. If it's nil, protoregistry.GlobalTypes is used.
+	Resolver interface {
+		protoregistry.ExtensionTypeResolver
+		protoregistry.MessageTypeResolver
+	}
+}
+
+// defaultJSONOptions holds the *JSONOptions set using SetDefaultJSONOptions.
+var defaultJSONOptions atomic.Value
+
+// SetDefaultJSONOptions sets the options used by AsJSON when no other options are given. For example,
+// Multiline can be set in development environments.
+func SetDefaultJSONOptions(opts *JSONOptions) {
+	defaultJSONOptions.Store(opts)
+}
+
+// DefaultJSONOptions returns the options set using SetDefaultJSONOptions, or nil if there aren't any.
+func DefaultJSONOptions() *JSONOptions {
+	opts, _ := defaultJSONOptions.Load().(*JSONOptions)
+	return opts
+}
+
+// marshalOptions returns the protojson options described by the options. If the options are nil,
+// the zero value protojson options are returned.
+func (o *JSONOptions) marshalOptions() protojson.MarshalOptions {
+	if o == nil {
+		return protojson.MarshalOptions{}
+	}
+
+	return protojson.MarshalOptions{
+		Multiline:       o.Multiline || o.Indent != "",
+		Indent:          o.Indent,
+		UseProtoNames:   o.UseProtoNames,
+		EmitUnpopulated: o.EmitUnpopulated,
+		Resolver:        o.Resolver,
+	}
+}
+
+// JSONOptions sets how the gRPC error is marshalled by AsJSON, which overrides the default options
+// set using SetDefaultJSONOptions.
+func (f *httpResponseEncoder) JSONOptions(opts *JSONOptions) *httpResponseEncoder {
+	f.jsonOptions = opts
+	return f
+}
+
+// jsonOptionsOrDefault returns the options set using JSONOptions(), or the default ones if there aren't any.
+func (f *httpResponseEncoder) jsonOptionsOrDefault() *JSONOptions {
+	if f.jsonOptions != nil {
+		return f.jsonOptions
+	}
+	return DefaultJSONOptions()
+}
@@ -0,0 +1,158 @@
+package grpcerr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tobbstr/testa/assert"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// customDetailErr returns a gRPC error with a detail of type dummy.CustomDetail, which isn't registered in
+// protoregistry.GlobalTypes, and a resolver which knows the type.
+func customDetailErr(t *testing.T) (error, *protoregistry.Types) {
+	t.Helper()
+
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("dummy/custom_detail.proto"),
+		Package: proto.String("dummy"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("CustomDetail"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String("dummy_field"),
+				JsonName: proto.String("dummyField"),
+				Number:   proto.Int32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			}},
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	messageType := dynamicpb.NewMessageType(file.Messages().Get(0))
+	resolver := new(protoregistry.Types)
+	if err := resolver.RegisterMessage(messageType); err != nil {
+		t.Fatal(err)
+	}
+
+	detail := messageType.New()
+	detail.Set(messageType.Descriptor().Fields().ByName("dummy_field"), protoreflect.ValueOfString("dummy-value"))
+	value, err := proto.Marshal(detail.Interface())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st := &spb.Status{
+		Code:    int32(codes.NotFound),
+		Message: "dummy-msg",
+		Details: []*anypb.Any{{TypeUrl: "type.googleapis.com/dummy.CustomDetail", Value: value}},
+	}
+	return status.FromProto(st).Err(), resolver
+}
+
+func TestHttpResponseEncodeWriteAsJSONOptions(t *testing.T) {
+	defer SetDefaultJSONOptions(nil)
+
+	badRequest := New(codes.InvalidArgument).Message("dummy-msg").FieldViolations([]FieldViolation{{Field: "dummy-field", Description: "dummy-description"}}).Err()
+	customDetail, resolver := customDetailErr(t)
+
+	tests := []struct {
+		name           string
+		defaultOptions *JSONOptions
+		options        *JSONOptions
+		gRPCErr        error
+		wantErr        bool
+		wantBody       string
+		wantIndent     string
+	}{
+		{
+			name:     "Should marshal like protojson when there are no options",
+			gRPCErr:  badRequest,
+			wantBody: `{"code": 3, "message": "dummy-msg", "details": [{"@type": "type.googleapis.com/google.rpc.BadRequest", "fieldViolations": [{"field": "dummy-field", "description": "dummy-description"}]}]}`,
+		},
+		{
+			name:     "Should use proto names",
+			options:  &JSONOptions{UseProtoNames: true},
+			gRPCErr:  badRequest,
+			wantBody: `{"code": 3, "message": "dummy-msg", "details": [{"@type": "type.googleapis.com/google.rpc.BadRequest", "field_violations": [{"field": "dummy-field", "description": "dummy-description"}]}]}`,
+		},
+		{
+			name:     "Should emit unpopulated fields",
+			options:  &JSONOptions{EmitUnpopulated: true},
+			gRPCErr:  New(codes.NotFound).Message("dummy-msg").Err(),
+			wantBody: `{"code": 5, "message": "dummy-msg", "details": []}`,
+		},
+		{
+			name:       "Should spread JSON over multiple lines",
+			options:    &JSONOptions{Multiline: true},
+			gRPCErr:    New(codes.NotFound).Message("dummy-msg").Err(),
+			wantBody:   `{"code": 5, "message": "dummy-msg"}`,
+			wantIndent: "\n  \"code\"",
+		},
+		{
+			name:       "Should indent using given indentation",
+			options:    &JSONOptions{Indent: "\t"},
+			gRPCErr:    New(codes.NotFound).Message("dummy-msg").Err(),
+			wantBody:   `{"code": 5, "message": "dummy-msg"}`,
+			wantIndent: "\n\t\"code\"",
+		},
+		{
+			name:    "Should fail when detail type can't be resolved",
+			gRPCErr: customDetail,
+			wantErr: true,
+		},
+		{
+			name:     "Should render custom detail using resolver",
+			options:  &JSONOptions{Resolver: resolver},
+			gRPCErr:  customDetail,
+			wantBody: `{"code": 5, "message": "dummy-msg", "details": [{"@type": "type.googleapis.com/dummy.CustomDetail", "dummyField": "dummy-value"}]}`,
+		},
+		{
+			name:           "Should use default options",
+			defaultOptions: &JSONOptions{UseProtoNames: true},
+			gRPCErr:        badRequest,
+			wantBody:       `{"code": 3, "message": "dummy-msg", "details": [{"@type": "type.googleapis.com/google.rpc.BadRequest", "field_violations": [{"field": "dummy-field", "description": "dummy-description"}]}]}`,
+		},
+		{
+			name:           "Should use given options which override default options",
+			defaultOptions: &JSONOptions{UseProtoNames: true},
+			options:        &JSONOptions{},
+			gRPCErr:        badRequest,
+			wantBody:       `{"code": 3, "message": "dummy-msg", "details": [{"@type": "type.googleapis.com/google.rpc.BadRequest", "fieldViolations": [{"field": "dummy-field", "description": "dummy-description"}]}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			SetDefaultJSONOptions(tt.defaultOptions)
+			w := httptest.NewRecorder()
+
+			// When
+			err := NewHttpResponseEncodeWriter(w)(tt.gRPCErr).JSONOptions(tt.options).AsJSON()
+
+			// Then
+			if tt.wantErr {
+				assert(err).IsNotNil()
+				assert(w.Code).Equals(http.StatusInternalServerError)
+				return
+			}
+			assert(err).IsNil()
+			assert(w.Body.String()).IsJSONEqualTo(tt.wantBody)
+			assert(strings.Contains(w.Body.String(), tt.wantIndent)).IsTrue()
+		})
+	}
+}
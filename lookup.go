@@ -0,0 +1,113 @@
+package grpcerr
+
+import (
+	"sync/atomic"
+
+	"google.golang.org/grpc/status"
+)
+
+// LookupPolicy decides which gRPC status is used when the chain of wrapped errors holds more than one.
+type LookupPolicy int32
+
+const (
+	// LookupOutermost uses the first gRPC status found when unwrapping the error, which is the one that
+	// errors.As would find. It's the default policy.
+	LookupOutermost LookupPolicy = iota
+	// LookupInnermost uses the last gRPC status found when unwrapping the error. For a chain without
+	// multi-cause errors, that is the one closest to the root error.
+	LookupInnermost
+)
+
+// lookupPolicy is the policy used by StatusFrom. It's accessed atomically.
+var lookupPolicy int32 = int32(LookupOutermost)
+
+// SetLookupPolicy sets the policy used by StatusFrom, and thereby by the HTTP response encoder and the
+// Code, Message and ...From functions, to find the gRPC status in a chain of wrapped errors.
+func SetLookupPolicy(policy LookupPolicy) {
+	atomic.StoreInt32(&lookupPolicy, int32(policy))
+}
+
+// StatusFrom returns the gRPC status held by err or any of the errors it wraps, according to the lookup
+// policy set using SetLookupPolicy. Errors are unwrapped depth-first using both the Unwrap() error and
+// Unwrap() []error methods. The boolean is false if there isn't any gRPC status.
+func StatusFrom(err error) (*status.Status, bool) {
+	statusErr, ok := statusErrorFrom(err)
+	if !ok {
+		return nil, false
+	}
+
+	return statusErr.GRPCStatus(), true
+}
+
+// convert returns the gRPC status held by err or any of the errors it wraps. If there isn't any, it
+// behaves like status.Convert.
+func convert(err error) *status.Status {
+	if st, ok := StatusFrom(err); ok {
+		return st
+	}
+
+	return status.Convert(err)
+}
+
+type grpcStatusError interface {
+	error
+	GRPCStatus() *status.Status
+}
+
+// statusErrorFrom returns the error in the chain of wrapped errors which holds the gRPC status,
+// according to the lookup policy.
+func statusErrorFrom(err error) (grpcStatusError, bool) {
+	return statusErrorWith(err, LookupPolicy(atomic.LoadInt32(&lookupPolicy)))
+}
+
+// outermostStatusFrom returns the first gRPC status found when unwrapping err, regardless of the lookup
+// policy. If there isn't any, it behaves like status.Convert.
+func outermostStatusFrom(err error) *status.Status {
+	if statusErr, ok := statusErrorWith(err, LookupOutermost); ok {
+		return statusErr.GRPCStatus()
+	}
+
+	return status.Convert(err)
+}
+
+// statusErrorWith returns the error in the chain of wrapped errors which holds the gRPC status,
+// according to the given lookup policy.
+func statusErrorWith(err error, policy LookupPolicy) (grpcStatusError, bool) {
+	var found grpcStatusError
+	innermost := policy == LookupInnermost
+
+	walkErrorChain(err, func(err error) bool {
+		statusErr, ok := err.(grpcStatusError)
+		if !ok || statusErr.GRPCStatus() == nil {
+			return true
+		}
+		found = statusErr
+		return innermost
+	})
+
+	return found, found != nil
+}
+
+// walkErrorChain calls fn for err and every error it wraps, depth-first, until fn returns false.
+// It returns false if the walk was stopped by fn.
+func walkErrorChain(err error, fn func(err error) bool) bool {
+	if err == nil {
+		return true
+	}
+	if !fn(err) {
+		return false
+	}
+
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return walkErrorChain(e.Unwrap(), fn)
+	case interface{ Unwrap() []error }:
+		for _, wrapped := range e.Unwrap() {
+			if !walkErrorChain(wrapped, fn) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,178 @@
+package grpcerr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tobbstr/testa/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// dummyWrappingError is a custom error type with its own Unwrap method.
+type dummyWrappingError struct {
+	err error
+}
+
+func (e *dummyWrappingError) Error() string { return "dummy-wrapping-error: " + e.err.Error() }
+func (e *dummyWrappingError) Unwrap() error { return e.err }
+
+// dummyMultiError is an error with multiple causes.
+type dummyMultiError struct {
+	errs []error
+}
+
+func (e *dummyMultiError) Error() string   { return "dummy-multi-error" }
+func (e *dummyMultiError) Unwrap() []error { return e.errs }
+
+func TestStatusFrom(t *testing.T) {
+	defer SetLookupPolicy(LookupOutermost)
+
+	notFound := status.Error(codes.NotFound, "dummy-not-found")
+	internal := New(codes.Internal).Message("dummy-internal").Cause(fmt.Errorf("dummy-wrapping-error: %w", notFound)).Err()
+
+	type args struct {
+		err    error
+		policy LookupPolicy
+	}
+	tests := []struct {
+		name     string
+		args     args
+		wantCode codes.Code
+		wantOK   bool
+	}{
+		{
+			name: "should return false when get error without gRPC status",
+			args: args{
+				err:    errors.New("dummy-error"),
+				policy: LookupOutermost,
+			},
+			wantCode: codes.OK,
+			wantOK:   false,
+		},
+		{
+			name: "should return false when get nil error",
+			args: args{
+				err:    nil,
+				policy: LookupOutermost,
+			},
+			wantCode: codes.OK,
+			wantOK:   false,
+		},
+		{
+			name: "should return status when get gRPC error wrapped by custom error type",
+			args: args{
+				err:    &dummyWrappingError{err: fmt.Errorf("dummy-wrapping-error: %w", notFound)},
+				policy: LookupOutermost,
+			},
+			wantCode: codes.NotFound,
+			wantOK:   true,
+		},
+		{
+			name: "should return status when get gRPC error which is not the root error",
+			args: args{
+				err:    fmt.Errorf("dummy-wrapping-error: %w", &dummyWrappingError{err: notFound}),
+				policy: LookupOutermost,
+			},
+			wantCode: codes.NotFound,
+			wantOK:   true,
+		},
+		{
+			name: "should return outermost status when get outermost policy",
+			args: args{
+				err:    fmt.Errorf("dummy-wrapping-error: %w", internal),
+				policy: LookupOutermost,
+			},
+			wantCode: codes.Internal,
+			wantOK:   true,
+		},
+		{
+			name: "should return innermost status when get innermost policy",
+			args: args{
+				err:    fmt.Errorf("dummy-wrapping-error: %w", internal),
+				policy: LookupInnermost,
+			},
+			wantCode: codes.NotFound,
+			wantOK:   true,
+		},
+		{
+			name: "should return status when get multi-cause error",
+			args: args{
+				err:    &dummyMultiError{errs: []error{errors.New("dummy-error"), &dummyWrappingError{err: notFound}}},
+				policy: LookupOutermost,
+			},
+			wantCode: codes.NotFound,
+			wantOK:   true,
+		},
+		{
+			name: "should return first status when get multi-cause error with outermost policy",
+			args: args{
+				err:    &dummyMultiError{errs: []error{status.Error(codes.Aborted, ""), notFound}},
+				policy: LookupOutermost,
+			},
+			wantCode: codes.Aborted,
+			wantOK:   true,
+		},
+		{
+			name: "should return last status when get multi-cause error with innermost policy",
+			args: args{
+				err:    &dummyMultiError{errs: []error{status.Error(codes.Aborted, ""), notFound}},
+				policy: LookupInnermost,
+			},
+			wantCode: codes.NotFound,
+			wantOK:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			SetLookupPolicy(tt.args.policy)
+
+			// When
+			got, ok := StatusFrom(tt.args.err)
+
+			// Then
+			assert(ok).Equals(tt.wantOK)
+			assert(got.Code()).Equals(tt.wantCode)
+		})
+	}
+}
+
+func TestAccessorsWithWrappedGRPCError(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+	notFound := New(codes.NotFound).
+		Message("dummy-msg").
+		ResourceInfo(&ResourceInfo{ResourceName: "dummy-resource-name"}).
+		Err()
+	err := &dummyWrappingError{err: fmt.Errorf("dummy-wrapping-error: %w", notFound)}
+
+	// When
+	code := Code(err)
+	msg := Message(err)
+	resourceInfo := ResourceInfoFrom(err)
+
+	// Then
+	assert(code).Equals(codes.NotFound)
+	assert(msg).Equals("dummy-msg")
+	assert(resourceInfo).Equals(ResourceInfo{ResourceName: "dummy-resource-name"})
+}
+
+func TestHttpResponseEncodeWriteAsJSONWithMultiCauseError(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+	w := httptest.NewRecorder()
+	err := &dummyMultiError{errs: []error{errors.New("dummy-error"), &dummyWrappingError{err: NewUnimplemented("dummy-msg")}}}
+
+	// When
+	gotErr := NewHttpResponseEncodeWriter(w)(err).AsJSON()
+
+	// Then
+	assert(gotErr).IsNil()
+	assert(w.Code).Equals(http.StatusNotImplemented)
+	assert(w.Body.String()).IsJSONEqualTo(`{"code":12, "message":"dummy-msg"}`)
+}
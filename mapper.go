@@ -0,0 +1,108 @@
+package grpcerr
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Mapper maps a Go error to a gRPC error. If the mapper does not handle the error,
+// it must return false.
+type Mapper func(err error) (gRPCErr error, ok bool)
+
+var (
+	mappersMu sync.RWMutex
+	// mappers holds the mappers registered using RegisterMapper. They take precedence over defaultMappers.
+	mappers []Mapper
+	// defaultMappers holds the mappers for common errors in the standard library.
+	defaultMappers = []Mapper{
+		SentinelMapper(context.Canceled, codes.Canceled),
+		SentinelMapper(context.DeadlineExceeded, codes.DeadlineExceeded),
+		SentinelMapper(os.ErrNotExist, codes.NotFound),
+		SentinelMapper(os.ErrPermission, codes.PermissionDenied),
+		netTimeoutMapper,
+		SentinelMapper(syscall.ECONNREFUSED, codes.Unavailable),
+	}
+)
+
+// RegisterMapper registers a mapper used by FromError. Registered mappers are tried in the order they
+// were registered and before the default mappers, which means they can override the default mappings.
+//
+// It's safe for concurrent use, but it's typically called during program initialization.
+//
+// Example:
+//
+//	grpcerr.RegisterMapper(grpcerr.SentinelMapper(sql.ErrNoRows, codes.NotFound))
+func RegisterMapper(mapper Mapper) {
+	mappersMu.Lock()
+	defer mappersMu.Unlock()
+
+	mappers = append(mappers, mapper)
+}
+
+// SentinelMapper returns a mapper which maps errors matching target, as reported by errors.Is, to a gRPC
+// error with the code and its default message. The mapped error is the cause of the returned gRPC error.
+func SentinelMapper(target error, code codes.Code) Mapper {
+	return func(err error) (error, bool) {
+		if !errors.Is(err, target) {
+			return nil, false
+		}
+		return New(code).Cause(err).errWithoutStack(), true
+	}
+}
+
+// netTimeoutMapper maps network errors caused by timeouts to DeadlineExceeded.
+func netTimeoutMapper(err error) (error, bool) {
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		return nil, false
+	}
+
+	return New(codes.DeadlineExceeded).Cause(err).errWithoutStack(), true
+}
+
+// FromError returns a gRPC error for err. If err is or wraps a gRPC error, that gRPC error is returned
+// according to the lookup policy set using SetLookupPolicy.
+// Otherwise it's mapped by the first registered mapper or default mapper that handles it. If none of the
+// mappers handle it, an Unknown gRPC error is returned. In both cases err is the cause of the returned
+// gRPC error. If err is nil, nil is returned.
+//
+// The default mappers map the following errors:
+//
+//	context.Canceled         -> Canceled
+//	context.DeadlineExceeded -> DeadlineExceeded
+//	os.ErrNotExist           -> NotFound
+//	os.ErrPermission         -> PermissionDenied
+//	net.Error timeouts       -> DeadlineExceeded
+//	syscall.ECONNREFUSED     -> Unavailable
+func FromError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if statusErr, ok := statusErrorFrom(err); ok {
+		return statusErr
+	}
+
+	mappersMu.RLock()
+	registered := mappers
+	mappersMu.RUnlock()
+
+	for _, mapper := range registered {
+		if gRPCErr, ok := mapper(err); ok {
+			return gRPCErr
+		}
+	}
+	for _, mapper := range defaultMappers {
+		if gRPCErr, ok := mapper(err); ok {
+			return gRPCErr
+		}
+	}
+
+	return New(codes.Unknown).Cause(err).errWithoutStack()
+}
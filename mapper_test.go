@@ -0,0 +1,143 @@
+package grpcerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/tobbstr/testa/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type dummyTimeoutError struct{}
+
+func (dummyTimeoutError) Error() string   { return "dummy-timeout" }
+func (dummyTimeoutError) Timeout() bool   { return true }
+func (dummyTimeoutError) Temporary() bool { return true }
+
+func TestFromError(t *testing.T) {
+	notFound := status.Error(codes.NotFound, "dummy-msg")
+
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+		wantMsg  string
+	}{
+		{
+			name:     "should return same gRPC error when get gRPC error",
+			err:      notFound,
+			wantCode: codes.NotFound,
+			wantMsg:  "dummy-msg",
+		},
+		{
+			name:     "should return wrapped gRPC error when get wrapped gRPC error",
+			err:      fmt.Errorf("dummy-wrapping-error: %w", notFound),
+			wantCode: codes.NotFound,
+			wantMsg:  "dummy-msg",
+		},
+		{
+			name:     "should return Canceled when get context.Canceled",
+			err:      fmt.Errorf("dummy-wrapping-error: %w", context.Canceled),
+			wantCode: codes.Canceled,
+			wantMsg:  defaultCanceledErrMsg,
+		},
+		{
+			name:     "should return DeadlineExceeded when get context.DeadlineExceeded",
+			err:      context.DeadlineExceeded,
+			wantCode: codes.DeadlineExceeded,
+			wantMsg:  defaultDeadlineExceededErrMsg,
+		},
+		{
+			name:     "should return NotFound when get os.ErrNotExist",
+			err:      &os.PathError{Op: "open", Path: "dummy-path", Err: os.ErrNotExist},
+			wantCode: codes.NotFound,
+			wantMsg:  defaultNotFoundErrMsg,
+		},
+		{
+			name:     "should return PermissionDenied when get os.ErrPermission",
+			err:      &os.PathError{Op: "open", Path: "dummy-path", Err: os.ErrPermission},
+			wantCode: codes.PermissionDenied,
+			wantMsg:  defaultPermissionDeniedErrMsg,
+		},
+		{
+			name:     "should return DeadlineExceeded when get net timeout",
+			err:      &net.OpError{Op: "read", Net: "tcp", Err: dummyTimeoutError{}},
+			wantCode: codes.DeadlineExceeded,
+			wantMsg:  defaultDeadlineExceededErrMsg,
+		},
+		{
+			name:     "should return Unavailable when get connection refused",
+			err:      &net.OpError{Op: "dial", Net: "tcp", Err: &os.SyscallError{Syscall: "connect", Err: syscall.ECONNREFUSED}},
+			wantCode: codes.Unavailable,
+			wantMsg:  defaultUnavailableErrMsg,
+		},
+		{
+			name:     "should return Unknown when get error which is not mapped",
+			err:      errors.New("dummy-error"),
+			wantCode: codes.Unknown,
+			wantMsg:  defaultUnknownErrMsg,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+
+			// When
+			got := FromError(tt.err)
+
+			// Then
+			assert(Code(got)).Equals(tt.wantCode)
+			assert(Message(got)).Equals(tt.wantMsg)
+		})
+	}
+}
+
+func TestFromErrorWithNil(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+
+	// When
+	got := FromError(nil)
+
+	// Then
+	assert(got).IsNil()
+}
+
+func TestFromErrorKeepsCause(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+	err := fmt.Errorf("dummy-wrapping-error: %w", context.Canceled)
+
+	// When
+	got := FromError(err)
+
+	// Then
+	assert(errors.Unwrap(got)).Equals(err)
+	assert(errors.Is(got, context.Canceled)).IsTrue()
+}
+
+func TestRegisterMapper(t *testing.T) {
+	defer func(registered []Mapper) { mappers = registered }(mappers)
+
+	errNoRows := errors.New("dummy-no-rows")
+
+	// Given
+	assert := assert.New(t)
+	RegisterMapper(SentinelMapper(errNoRows, codes.NotFound))
+	RegisterMapper(SentinelMapper(context.Canceled, codes.Aborted))
+
+	// When
+	gotNoRows := FromError(fmt.Errorf("dummy-wrapping-error: %w", errNoRows))
+	gotCanceled := FromError(context.Canceled)
+
+	// Then
+	assert(Code(gotNoRows)).Equals(codes.NotFound)
+	assert(Code(gotCanceled)).Equals(codes.Aborted)
+}
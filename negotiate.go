@@ -0,0 +1,96 @@
+package grpcerr
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// acceptedMediaRange is a media range of an Accept header, such as "application/*;q=0.8".
+type acceptedMediaRange struct {
+	typ     string
+	subtype string
+	q       float64
+}
+
+// negotiateMediaType returns the offered media type which is most preferred by the Accept header. If
+// several offers are equally preferred, the first one of them is returned. If the Accept header is empty,
+// the first offer is returned, and if none of the offers are acceptable, the empty string is returned.
+func negotiateMediaType(accept string, offers []string) string {
+	if strings.TrimSpace(accept) == "" {
+		if len(offers) == 0 {
+			return ""
+		}
+		return offers[0]
+	}
+
+	ranges := parseAccept(accept)
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := qualityOf(offer, ranges); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+
+	return best
+}
+
+// qualityOf returns the q-value of the most specific media range matching the media type, or 0 if none
+// of them matches.
+func qualityOf(mediaType string, ranges []acceptedMediaRange) float64 {
+	typ, subtype, _ := splitMediaType(mediaType)
+
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		var s int
+		switch {
+		case r.typ == typ && r.subtype == subtype:
+			s = 2
+		case r.typ == typ && r.subtype == "*":
+			s = 1
+		case r.typ == "*" && r.subtype == "*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+
+	return q
+}
+
+// parseAccept parses the media ranges of an Accept header. Invalid media ranges are skipped.
+func parseAccept(accept string) []acceptedMediaRange {
+	var ranges []acceptedMediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		typ, subtype, ok := splitMediaType(mediaType)
+		if !ok {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil && parsed >= 0 && parsed <= 1 {
+				q = parsed
+			}
+		}
+		ranges = append(ranges, acceptedMediaRange{typ: typ, subtype: subtype, q: q})
+	}
+
+	return ranges
+}
+
+// splitMediaType splits a media type into its type and subtype. The boolean is false if it has no subtype.
+func splitMediaType(mediaType string) (typ, subtype string, ok bool) {
+	i := strings.Index(mediaType, "/")
+	if i < 0 {
+		return mediaType, "", false
+	}
+	return mediaType[:i], mediaType[i+1:], true
+}
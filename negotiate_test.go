@@ -0,0 +1,32 @@
+package grpcerr
+
+import (
+	"testing"
+
+	"github.com/tobbstr/testa/assert"
+)
+
+func Test_negotiateMediaType(t *testing.T) {
+	offers := []string{contentTypeJSON, contentTypeProtobuf}
+
+	tests := []struct {
+		name   string
+		accept string
+		want   string
+	}{
+		{name: "should return first offer when get empty Accept header", accept: "", want: contentTypeJSON},
+		{name: "should return first offer when get any media type", accept: "*/*", want: contentTypeJSON},
+		{name: "should return exact match", accept: "application/x-protobuf", want: contentTypeProtobuf},
+		{name: "should return offer with highest q-value", accept: "application/json;q=0.5, application/x-protobuf", want: contentTypeProtobuf},
+		{name: "should prefer specific media range over wildcard", accept: "application/*;q=0.2, application/x-protobuf;q=0.9", want: contentTypeProtobuf},
+		{name: "should return first offer when equally preferred", accept: "application/*", want: contentTypeJSON},
+		{name: "should skip offers with zero q-value", accept: "application/json;q=0, */*;q=0.1", want: contentTypeProtobuf},
+		{name: "should return empty string when no offer is acceptable", accept: "text/html", want: ""},
+		{name: "should skip invalid media ranges", accept: "invalid;;, application/x-protobuf", want: contentTypeProtobuf},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.New(t)(negotiateMediaType(tt.accept, offers)).Equals(tt.want)
+		})
+	}
+}
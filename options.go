@@ -0,0 +1,92 @@
+package grpcerr
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/status"
+)
+
+// StatusResponseWriter is the http.ResponseWriter passed to ResponseWriterOptions by the HTTP response
+// encoder. Besides the http.ResponseWriter, it holds the gRPC status being written and the HTTP status
+// code it's sent with, which allows options to depend on the error's code, reason or details.
+//
+// Options can be tested in isolation by passing them a StatusResponseWriter, for example:
+//
+//	w := &grpcerr.StatusResponseWriter{ResponseWriter: httptest.NewRecorder(), Status: st}
+//	grpcerr.WithRequestIDHeader()(w)
+type StatusResponseWriter struct {
+	http.ResponseWriter
+	// Status is the gRPC status being written, after it's been masked and redacted.
+	Status *status.Status
+	// StatusCode is the HTTP status code which is sent. Options may change it, either directly or by
+	// calling WriteHeader. Note that the body is encoded before the options are applied, so bodies which
+	// hold the HTTP status code, such as problem JSON, keep the original one. Use MapStatus to change both.
+	StatusCode int
+}
+
+// WriteHeader records the HTTP status code in StatusCode rather than sending it, since the response
+// encoder sends it after all options are applied.
+func (w *StatusResponseWriter) WriteHeader(statusCode int) {
+	w.StatusCode = statusCode
+}
+
+// Unwrap returns the wrapped http.ResponseWriter, which allows http.ResponseController to access it.
+func (w *StatusResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// withStatusResponseWriter returns an option which calls fn if its argument is a *StatusResponseWriter.
+// Otherwise the option does nothing.
+func withStatusResponseWriter(fn func(w *StatusResponseWriter)) ResponseWriterOption {
+	return func(w http.ResponseWriter) {
+		if sw, ok := w.(*StatusResponseWriter); ok {
+			fn(sw)
+		}
+	}
+}
+
+// WithHeader sets the header to the value, replacing any existing values.
+func WithHeader(key, value string) ResponseWriterOption {
+	return func(w http.ResponseWriter) {
+		w.Header().Set(key, value)
+	}
+}
+
+// WithStatus sets the HTTP status code, which overrides the one derived from the gRPC error.
+// The option does nothing unless it's given a *StatusResponseWriter, as it is by the HTTP response encoder.
+func WithStatus(httpStatusCode int) ResponseWriterOption {
+	return withStatusResponseWriter(func(w *StatusResponseWriter) {
+		w.StatusCode = httpStatusCode
+	})
+}
+
+// WithStatusFunc sets the HTTP status code to the one returned by fn for the gRPC status, which overrides
+// the one derived from the gRPC error. If fn returns 0, the HTTP status code is left as is.
+// The option does nothing unless it's given a *StatusResponseWriter, as it is by the HTTP response encoder.
+//
+// Example:
+//
+//	withGoneForDeletedUsers := grpcerr.WithStatusFunc(func(st *status.Status) int {
+//	    if grpcerr.ErrorInfoFrom(st.Err()).Reason == "USER_DELETED" {
+//	        return http.StatusGone
+//	    }
+//	    return 0
+//	})
+func WithStatusFunc(fn func(st *status.Status) int) ResponseWriterOption {
+	return withStatusResponseWriter(func(w *StatusResponseWriter) {
+		if httpStatusCode := fn(w.Status); httpStatusCode != 0 {
+			w.StatusCode = httpStatusCode
+		}
+	})
+}
+
+// WithRequestIDHeader sets the X-Request-ID header to the request ID of the gRPC error's RequestInfo, if
+// it has one. The option does nothing unless it's given a *StatusResponseWriter, as it is by the HTTP
+// response encoder.
+func WithRequestIDHeader() ResponseWriterOption {
+	return withStatusResponseWriter(func(w *StatusResponseWriter) {
+		if requestID := RequestInfoFrom(w.Status.Err()).RequestID; requestID != "" {
+			w.Header().Set(HeaderRequestID, requestID)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package grpcerr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tobbstr/testa/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestResponseWriterOptions(t *testing.T) {
+	withRequestID := New(codes.NotFound).RequestInfo(&RequestInfo{RequestID: "dummy-request-id"}).Status()
+	withReason := New(codes.NotFound).ErrorInfo(&ErrorInfo{Reason: "DUMMY_REASON", Domain: "dummy-domain"}).Status()
+	goneForDummyReason := func(st *status.Status) int {
+		if ErrorInfoFrom(st.Err()).Reason == "DUMMY_REASON" {
+			return http.StatusGone
+		}
+		return 0
+	}
+
+	tests := []struct {
+		name           string
+		opt            ResponseWriterOption
+		st             *status.Status
+		wantStatusCode int
+		wantHeader     http.Header
+	}{
+		{
+			name:           "Should set header",
+			opt:            WithHeader("X-Dummy", "dummy-value"),
+			st:             withReason,
+			wantStatusCode: http.StatusNotFound,
+			wantHeader:     http.Header{"X-Dummy": []string{"dummy-value"}},
+		},
+		{
+			name:           "Should set status code",
+			opt:            WithStatus(http.StatusOK),
+			st:             withReason,
+			wantStatusCode: http.StatusOK,
+			wantHeader:     http.Header{},
+		},
+		{
+			name:           "Should set status code returned by function",
+			opt:            WithStatusFunc(goneForDummyReason),
+			st:             withReason,
+			wantStatusCode: http.StatusGone,
+			wantHeader:     http.Header{},
+		},
+		{
+			name:           "Should keep status code when function returns zero",
+			opt:            WithStatusFunc(goneForDummyReason),
+			st:             withRequestID,
+			wantStatusCode: http.StatusNotFound,
+			wantHeader:     http.Header{},
+		},
+		{
+			name:           "Should record status code written by option",
+			opt:            func(w http.ResponseWriter) { w.WriteHeader(http.StatusOK) },
+			st:             withReason,
+			wantStatusCode: http.StatusOK,
+			wantHeader:     http.Header{},
+		},
+		{
+			name:           "Should set request ID header",
+			opt:            WithRequestIDHeader(),
+			st:             withRequestID,
+			wantStatusCode: http.StatusNotFound,
+			wantHeader:     http.Header{"X-Request-Id": []string{"dummy-request-id"}},
+		},
+		{
+			name:           "Should not set request ID header when there is no request ID",
+			opt:            WithRequestIDHeader(),
+			st:             withReason,
+			wantStatusCode: http.StatusNotFound,
+			wantHeader:     http.Header{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			w := &StatusResponseWriter{ResponseWriter: httptest.NewRecorder(), Status: tt.st, StatusCode: http.StatusNotFound}
+
+			// When
+			tt.opt(w)
+
+			// Then
+			assert(w.StatusCode).Equals(tt.wantStatusCode)
+			assert(w.Header()).Equals(tt.wantHeader)
+		})
+	}
+}
+
+func TestHttpResponseEncodeWriteWithResponseWriterOptions(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+	w := httptest.NewRecorder()
+	gRPCErr := New(codes.NotFound).RequestInfo(&RequestInfo{RequestID: "dummy-request-id"}).Err()
+	legacyOpt := func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/vnd.dummy+json")
+		w.WriteHeader(http.StatusOK)
+	}
+	encodeAndWrite := NewHttpResponseEncodeWriter(w, WithStatus(http.StatusGone), legacyOpt, WithRequestIDHeader())
+
+	// When
+	err := encodeAndWrite(gRPCErr).AsJSON()
+
+	// Then
+	assert(err).IsNil()
+	assert(w.Code).Equals(http.StatusOK)
+	assert(w.Header().Get("Content-Type")).Equals("application/vnd.dummy+json")
+	assert(w.Header().Get("X-Request-ID")).Equals("dummy-request-id")
+}
@@ -0,0 +1,162 @@
+package grpcerr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	contentTypeProblemJSON = "application/problem+json"
+	// errorInfoProblemTypePrefix prefixes the non-dereferenceable problem types derived from ErrorInfos.
+	errorInfoProblemTypePrefix = "urn:grpcerr:"
+)
+
+// problemDetails is an RFC 9457 problem details object. Besides the standard members, it has extension
+// members for the ErrorInfo, BadRequest, QuotaFailure and RequestInfo details.
+type problemDetails struct {
+	Type   string `json:"type,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Status int    `json:"status,omitempty"`
+	Detail string `json:"detail,omitempty"`
+
+	Reason          string                  `json:"reason,omitempty"`
+	Domain          string                  `json:"domain,omitempty"`
+	Metadata        map[string]string       `json:"metadata,omitempty"`
+	FieldViolations []problemFieldViolation `json:"fieldViolations,omitempty"`
+	QuotaViolations []problemQuotaViolation `json:"quotaViolations,omitempty"`
+	RequestID       string                  `json:"requestId,omitempty"`
+	ServingData     string                  `json:"servingData,omitempty"`
+}
+
+type problemFieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+type problemQuotaViolation struct {
+	Subject     string `json:"subject"`
+	Description string `json:"description"`
+}
+
+// AsProblemJSON encodes the gRPC error as an RFC 9457 problem details object and writes it to the
+// http.ResponseWriter, with the Content-Type application/problem+json. If the error isn't a gRPC error,
+// it's mapped to one using FromError.
+//
+// The members of the problem details object are:
+//
+//	type    "urn:grpcerr:<domain>:<reason>" from the ErrorInfo, the URL of the first help link if there's
+//	        no ErrorInfo reason, or "about:blank" if there's neither
+//	title   the name of the code, for example "NotFound"
+//	status  the HTTP status code
+//	detail  the message
+//
+// The ErrorInfo, field violations, quota violations and RequestInfo are added as extension members.
+// Other details are left out.
+// If an error occurs it is returned, otherwise it returns nil.
+func (f *httpResponseEncoder) AsProblemJSON() error {
+	return f.Encode(problemJSONEncoder{})
+}
+
+// problemJSONEncoder is the Encoder used by AsProblemJSON.
+type problemJSONEncoder struct{}
+
+func (problemJSONEncoder) ContentType() string {
+	return contentTypeProblemJSON
+}
+
+func (problemJSONEncoder) Encode(st *status.Status, httpStatusCode int, _ http.Header) ([]byte, error) {
+	data, err := json.Marshal(problemDetailsFrom(st, httpStatusCode))
+	if err != nil {
+		return nil, fmt.Errorf("could not get problem JSON as bytes from gRPC status: %w", err)
+	}
+	return data, nil
+}
+
+func problemDetailsFrom(st *status.Status, httpStatusCode int) problemDetails {
+	gRPCErr := st.Err()
+	errorInfo := ErrorInfoFrom(gRPCErr)
+	requestInfo := RequestInfoFrom(gRPCErr)
+
+	problem := problemDetails{
+		Type:        problemTypeFrom(errorInfo, HelpLinksFrom(gRPCErr)),
+		Title:       st.Code().String(),
+		Status:      httpStatusCode,
+		Detail:      st.Message(),
+		Reason:      errorInfo.Reason,
+		Domain:      errorInfo.Domain,
+		Metadata:    errorInfo.Metadata,
+		RequestID:   requestInfo.RequestID,
+		ServingData: requestInfo.ServingData,
+	}
+	for _, violation := range FieldViolationsFrom(gRPCErr) {
+		problem.FieldViolations = append(problem.FieldViolations, problemFieldViolation(violation))
+	}
+	for _, violation := range QuotaViolationsFrom(gRPCErr) {
+		problem.QuotaViolations = append(problem.QuotaViolations, problemQuotaViolation(violation))
+	}
+
+	return problem
+}
+
+// problemTypeFrom returns the problem type. If there's an ErrorInfo reason, it's a URN built from the
+// ErrorInfo's domain and reason, which isn't meant to be dereferenced, rather than an invented https URL.
+// Otherwise it's the URL of the first help link. If there's neither, "about:blank" is returned.
+func problemTypeFrom(errorInfo ErrorInfo, helpLinks []HelpLink) string {
+	switch {
+	case errorInfo.Reason != "" && errorInfo.Domain != "":
+		return errorInfoProblemTypePrefix + errorInfo.Domain + ":" + errorInfo.Reason
+	case errorInfo.Reason != "":
+		return errorInfoProblemTypePrefix + errorInfo.Reason
+	case len(helpLinks) > 0 && helpLinks[0].URL != "":
+		return helpLinks[0].URL
+	}
+
+	return "about:blank"
+}
+
+// StatusFromProblemJSON returns the gRPC status held by an RFC 9457 problem details object, which is the
+// inverse of encoding a gRPC error using AsProblemJSON. The code is parsed from the title, or derived
+// from the HTTP status code if the title isn't the name of a code. Unless the type is "about:blank" or
+// was derived from an ErrorInfo, it's kept as a help link.
+func StatusFromProblemJSON(data []byte) (*status.Status, error) {
+	var problem problemDetails
+	if err := json.Unmarshal(data, &problem); err != nil {
+		return nil, fmt.Errorf("could not unmarshal problem JSON: %w", err)
+	}
+
+	code, ok := parseCode(problem.Title)
+	if !ok && problem.Status != 0 {
+		code, ok = CodeFromHTTPStatus(problem.Status), true
+	}
+	if !ok || code == codes.OK {
+		return nil, fmt.Errorf("invalid argument: problem JSON has neither an error title nor an error status")
+	}
+
+	b := New(code).Message(problem.Detail)
+	if problem.Reason != "" {
+		b.ErrorInfo(&ErrorInfo{Reason: problem.Reason, Domain: problem.Domain, Metadata: problem.Metadata})
+	}
+	if problem.Type != "" && problem.Type != "about:blank" && !strings.HasPrefix(problem.Type, errorInfoProblemTypePrefix) {
+		b.Help([]HelpLink{{URL: problem.Type}})
+	}
+	fieldViolations := make([]FieldViolation, 0, len(problem.FieldViolations))
+	for _, violation := range problem.FieldViolations {
+		fieldViolations = append(fieldViolations, FieldViolation(violation))
+	}
+	b.FieldViolations(fieldViolations)
+	quotaViolations := make([]QuotaViolation, 0, len(problem.QuotaViolations))
+	for _, violation := range problem.QuotaViolations {
+		quotaViolations = append(quotaViolations, QuotaViolation(violation))
+	}
+	b.QuotaViolations(quotaViolations)
+	if problem.RequestID != "" || problem.ServingData != "" {
+		b.RequestInfo(&RequestInfo{RequestID: problem.RequestID, ServingData: problem.ServingData})
+	}
+
+	return b.Status(), nil
+}
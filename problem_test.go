@@ -0,0 +1,209 @@
+package grpcerr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tobbstr/testa/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestHttpResponseEncodeWriteAsProblemJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		gRPCErr error
+		want    string
+	}{
+		{
+			name: "Should derive type from ErrorInfo and add extension members",
+			gRPCErr: New(codes.InvalidArgument).
+				Message("dummy-msg").
+				ErrorInfo(&ErrorInfo{Reason: "DUMMY_REASON", Domain: "dummy.example.com", Metadata: map[string]string{"dummy-key": "dummy-value"}}).
+				FieldViolations([]FieldViolation{{Field: "dummy-field", Description: "dummy-desc"}}).
+				RequestInfo(&RequestInfo{RequestID: "dummy-request-id"}).
+				DebugInfo(&DebugInfo{Detail: "dummy-detail"}).
+				Err(),
+			want: `{
+				"type": "urn:grpcerr:dummy.example.com:DUMMY_REASON",
+				"title": "InvalidArgument",
+				"status": 400,
+				"detail": "dummy-msg",
+				"reason": "DUMMY_REASON",
+				"domain": "dummy.example.com",
+				"metadata": {"dummy-key": "dummy-value"},
+				"fieldViolations": [{"field": "dummy-field", "description": "dummy-desc"}],
+				"requestId": "dummy-request-id"
+			}`,
+		},
+		{
+			name: "Should derive type from first help link when there is no ErrorInfo",
+			gRPCErr: New(codes.ResourceExhausted).
+				Message("dummy-msg").
+				Help([]HelpLink{{Description: "dummy-desc", URL: "https://dummy.example.com/help"}}).
+				QuotaViolations([]QuotaViolation{{Subject: "dummy-subject", Description: "dummy-desc"}}).
+				Err(),
+			want: `{
+				"type": "https://dummy.example.com/help",
+				"title": "ResourceExhausted",
+				"status": 429,
+				"detail": "dummy-msg",
+				"quotaViolations": [{"subject": "dummy-subject", "description": "dummy-desc"}]
+			}`,
+		},
+		{
+			name: "Should derive type from ErrorInfo rather than help link",
+			gRPCErr: New(codes.FailedPrecondition).
+				Message("dummy-msg").
+				ErrorInfo(&ErrorInfo{Reason: "DUMMY_REASON", Domain: "dummy.example.com"}).
+				Help([]HelpLink{{URL: "https://dummy.example.com/help"}, {URL: "https://dummy.example.com/other-help"}}).
+				Err(),
+			want: `{
+				"type": "urn:grpcerr:dummy.example.com:DUMMY_REASON",
+				"title": "FailedPrecondition",
+				"status": 400,
+				"detail": "dummy-msg",
+				"reason": "DUMMY_REASON",
+				"domain": "dummy.example.com"
+			}`,
+		},
+		{
+			name:    "Should derive type from ErrorInfo reason when there is no domain",
+			gRPCErr: New(codes.NotFound).Message("dummy-msg").ErrorInfo(&ErrorInfo{Reason: "DUMMY_REASON"}).Err(),
+			want:    `{"type": "urn:grpcerr:DUMMY_REASON", "title": "NotFound", "status": 404, "detail": "dummy-msg", "reason": "DUMMY_REASON"}`,
+		},
+		{
+			name:    "Should use about:blank type when there are no details",
+			gRPCErr: New(codes.NotFound).Message("dummy-msg").Err(),
+			want:    `{"type": "about:blank", "title": "NotFound", "status": 404, "detail": "dummy-msg"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			w := httptest.NewRecorder()
+
+			// When
+			err := NewHttpResponseEncodeWriter(w)(tt.gRPCErr).AsProblemJSON()
+
+			// Then
+			assert(err).IsNil()
+			assert(w.Header().Get("Content-Type")).Equals("application/problem+json")
+			assert(w.Body.String()).IsJSONEqualTo(tt.want)
+		})
+	}
+}
+
+func TestHttpResponseEncodeWriteAsProblemJSONUsesMappedStatus(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+	w := httptest.NewRecorder()
+	mapping := &HTTPStatusMapping{Codes: map[codes.Code]int{codes.InvalidArgument: http.StatusUnprocessableEntity}}
+
+	// When
+	err := NewHttpResponseEncodeWriter(w)(New(codes.InvalidArgument).Message("dummy-msg").Err()).MapStatus(mapping).AsProblemJSON()
+
+	// Then
+	assert(err).IsNil()
+	assert(w.Code).Equals(http.StatusUnprocessableEntity)
+	assert(w.Body.String()).IsJSONEqualTo(`{"type": "about:blank", "title": "InvalidArgument", "status": 422, "detail": "dummy-msg"}`)
+}
+
+func TestStatusFromProblemJSON(t *testing.T) {
+	tests := []struct {
+		name                string
+		data                string
+		wantErr             bool
+		wantCode            codes.Code
+		wantMsg             string
+		wantErrorInfo       ErrorInfo
+		wantHelpLinks       []HelpLink
+		wantFieldViolations []FieldViolation
+		wantQuotaViolations []QuotaViolation
+		wantRequestInfo     RequestInfo
+	}{
+		{
+			name: "should return status with details when get problem encoded using AsProblemJSON",
+			data: `{
+				"type": "urn:grpcerr:dummy.example.com:DUMMY_REASON",
+				"title": "InvalidArgument",
+				"status": 400,
+				"detail": "dummy-msg",
+				"reason": "DUMMY_REASON",
+				"domain": "dummy.example.com",
+				"fieldViolations": [{"field": "dummy-field", "description": "dummy-desc"}],
+				"quotaViolations": [{"subject": "dummy-subject", "description": "dummy-desc"}],
+				"requestId": "dummy-request-id"
+			}`,
+			wantCode:            codes.InvalidArgument,
+			wantMsg:             "dummy-msg",
+			wantErrorInfo:       ErrorInfo{Reason: "DUMMY_REASON", Domain: "dummy.example.com"},
+			wantHelpLinks:       []HelpLink{},
+			wantFieldViolations: []FieldViolation{{Field: "dummy-field", Description: "dummy-desc"}},
+			wantQuotaViolations: []QuotaViolation{{Subject: "dummy-subject", Description: "dummy-desc"}},
+			wantRequestInfo:     RequestInfo{RequestID: "dummy-request-id"},
+		},
+		{
+			name:                "should derive code from status and keep type as help link when get foreign problem",
+			data:                `{"type": "https://dummy.example.com/out-of-credit", "title": "You do not have enough credit.", "status": 403, "detail": "dummy-msg"}`,
+			wantCode:            codes.PermissionDenied,
+			wantMsg:             "dummy-msg",
+			wantHelpLinks:       []HelpLink{{URL: "https://dummy.example.com/out-of-credit"}},
+			wantFieldViolations: []FieldViolation{},
+			wantQuotaViolations: []QuotaViolation{},
+		},
+		{
+			name:    "should return error when get problem without error title or status",
+			data:    `{"type": "about:blank"}`,
+			wantErr: true,
+		},
+		{
+			name:    "should return error when get invalid JSON",
+			data:    `dummy-data`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+
+			// When
+			st, err := StatusFromProblemJSON([]byte(tt.data))
+
+			// Then
+			if tt.wantErr {
+				assert(err).IsNotNil()
+				return
+			}
+			assert(err).IsNil()
+			got := st.Err()
+			assert(Code(got)).Equals(tt.wantCode)
+			assert(Message(got)).Equals(tt.wantMsg)
+			assert(ErrorInfoFrom(got)).Equals(tt.wantErrorInfo)
+			assert(HelpLinksFrom(got)).Equals(tt.wantHelpLinks)
+			assert(FieldViolationsFrom(got)).Equals(tt.wantFieldViolations)
+			assert(QuotaViolationsFrom(got)).Equals(tt.wantQuotaViolations)
+			assert(RequestInfoFrom(got)).Equals(tt.wantRequestInfo)
+		})
+	}
+}
+
+func TestFromHTTPResponseProblemJSON(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+	w := httptest.NewRecorder()
+	gRPCErr := New(codes.NotFound).Message("dummy-msg").RequestInfo(&RequestInfo{RequestID: "dummy-request-id"}).Err()
+	if err := NewHttpResponseEncodeWriter(w)(gRPCErr).AsProblemJSON(); err != nil {
+		t.Fatal(err)
+	}
+
+	// When
+	got := FromHTTPResponse(w.Result())
+
+	// Then
+	assert(Code(got)).Equals(codes.NotFound)
+	assert(Message(got)).Equals("dummy-msg")
+	assert(RequestInfoFrom(got)).Equals(RequestInfo{RequestID: "dummy-request-id"})
+}
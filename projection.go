@@ -0,0 +1,151 @@
+package grpcerr
+
+import (
+	"encoding/json"
+
+	cpb "google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// JSON-RPC 2.0 error codes. The codes from -32000 to -32099 are reserved for implementation-defined
+// server errors.
+const (
+	jsonRPCMethodNotFound = -32601
+	jsonRPCInvalidParams  = -32602
+	jsonRPCInternalError  = -32603
+	jsonRPCServerError    = -32000
+)
+
+// JSONRPCError is a JSON-RPC 2.0 error object.
+type JSONRPCError struct {
+	Code    int              `json:"code"`
+	Message string           `json:"message"`
+	Data    JSONRPCErrorData `json:"data"`
+}
+
+// JSONRPCErrorData is the data of a JSON-RPC 2.0 error object, which holds the gRPC status.
+type JSONRPCErrorData struct {
+	// The name of the gRPC code, for example "NOT_FOUND".
+	Status string `json:"status"`
+	// The details, JSON encoded like in AsJSON.
+	Details []json.RawMessage `json:"details,omitempty"`
+}
+
+// JSONRPCErrorFrom projects a gRPC error into a JSON-RPC 2.0 error object. InvalidArgument, Unimplemented
+// and Internal errors get the JSON-RPC codes for invalid params, method not found and internal error.
+// Other errors get a code in the range reserved for server errors, which is -32000 minus the gRPC code,
+// for example -32005 for NotFound. The data holds the name of the gRPC code and the details.
+//
+// If the error isn't a gRPC error, it's mapped to one using FromError. Like the HTTP response encoder, server
+// faults are then masked using DefaultMasking and the status is redacted using DefaultRedactionPolicy.
+// A nil error is projected as an Internal error. Details whose types aren't linked into the binary are
+// left out.
+//
+// Example:
+//
+//	resp := Response{JSONRPC: "2.0", ID: req.ID, Error: grpcerr.JSONRPCErrorFrom(err)}
+func JSONRPCErrorFrom(gRPCErr error) JSONRPCError {
+	st := projectedStatusFrom("jsonrpc", gRPCErr)
+
+	return JSONRPCError{
+		Code:    jsonRPCCodeFrom(st.Code()),
+		Message: st.Message(),
+		Data: JSONRPCErrorData{
+			Status:  cpb.Code(st.Code()).String(),
+			Details: jsonDetailsFrom(st),
+		},
+	}
+}
+
+func jsonRPCCodeFrom(code codes.Code) int {
+	switch code {
+	case codes.InvalidArgument:
+		return jsonRPCInvalidParams
+	case codes.Unimplemented:
+		return jsonRPCMethodNotFound
+	case codes.Internal:
+		return jsonRPCInternalError
+	}
+
+	return jsonRPCServerError - int(code)
+}
+
+// projectedStatusFrom returns the status of the gRPC error as it's sent to clients, see clientStatusFrom.
+// A nil error results in an Internal status, since there's nothing to project.
+func projectedStatusFrom(source string, gRPCErr error) *status.Status {
+	if gRPCErr == nil {
+		gRPCErr = New(codes.Internal).errWithoutStack()
+	}
+
+	return clientStatusFrom(source, gRPCErr, DefaultMasking(), DefaultRedactionPolicy())
+}
+
+// jsonDetailsFrom returns the JSON encoded details of the status. Details which can't be encoded, because
+// their types aren't linked into the binary, are left out.
+func jsonDetailsFrom(st *status.Status) []json.RawMessage {
+	var details []json.RawMessage
+	for _, detail := range st.Proto().GetDetails() {
+		data, err := protojson.Marshal(detail)
+		if err != nil {
+			continue
+		}
+		details = append(details, data)
+	}
+
+	return details
+}
+
+// GraphQLError is an entry of the errors of a GraphQL response.
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions GraphQLErrorExtensions `json:"extensions"`
+}
+
+// GraphQLErrorExtensions are the extensions of a GraphQL error, which hold the gRPC status.
+type GraphQLErrorExtensions struct {
+	// The name of the gRPC code, for example "NOT_FOUND".
+	Code string `json:"code"`
+	// The ErrorInfo's reason.
+	Reason string `json:"reason,omitempty"`
+	// The field violations of the BadRequest detail.
+	FieldViolations []GraphQLFieldViolation `json:"fieldViolations,omitempty"`
+}
+
+// GraphQLFieldViolation is a field violation in the extensions of a GraphQL error.
+type GraphQLFieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// GraphQLErrorFrom projects a gRPC error into an entry of the errors of a GraphQL response. The path is
+// the path of the response field which experienced the error, made of field names and list indices.
+// The extensions hold the name of the gRPC code, the ErrorInfo's reason and the field violations.
+//
+// If the error isn't a gRPC error, it's mapped to one using FromError. Like the HTTP response encoder, server
+// faults are then masked using DefaultMasking and the status is redacted using DefaultRedactionPolicy.
+// A nil error is projected as an Internal error.
+//
+// Example:
+//
+//	resp.Errors = append(resp.Errors, grpcerr.GraphQLErrorFrom(err, []interface{}{"user", "friends", 1}))
+func GraphQLErrorFrom(gRPCErr error, path []interface{}) GraphQLError {
+	st := projectedStatusFrom("graphql", gRPCErr)
+	gRPCErr = st.Err()
+
+	graphQLErr := GraphQLError{
+		Message: st.Message(),
+		Path:    path,
+		Extensions: GraphQLErrorExtensions{
+			Code:   cpb.Code(st.Code()).String(),
+			Reason: ErrorInfoFrom(gRPCErr).Reason,
+		},
+	}
+	for _, violation := range FieldViolationsFrom(gRPCErr) {
+		graphQLErr.Extensions.FieldViolations = append(graphQLErr.Extensions.FieldViolations, GraphQLFieldViolation(violation))
+	}
+
+	return graphQLErr
+}
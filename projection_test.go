@@ -0,0 +1,164 @@
+package grpcerr
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/tobbstr/testa/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestJSONRPCErrorFrom(t *testing.T) {
+	tests := []struct {
+		name    string
+		gRPCErr error
+		want    string
+	}{
+		{
+			name: "should project InvalidArgument error to invalid params with details",
+			gRPCErr: New(codes.InvalidArgument).
+				Message("dummy-msg").
+				FieldViolations([]FieldViolation{{Field: "dummy-field", Description: "dummy-desc"}}).
+				Err(),
+			want: `{
+				"code": -32602,
+				"message": "dummy-msg",
+				"data": {
+					"status": "INVALID_ARGUMENT",
+					"details": [{"@type": "type.googleapis.com/google.rpc.BadRequest", "fieldViolations": [{"field": "dummy-field", "description": "dummy-desc"}]}]
+				}
+			}`,
+		},
+		{
+			name:    "should project Unimplemented error to method not found",
+			gRPCErr: New(codes.Unimplemented).Message("dummy-msg").Err(),
+			want:    `{"code": -32601, "message": "dummy-msg", "data": {"status": "UNIMPLEMENTED"}}`,
+		},
+		{
+			name:    "should project Internal error to internal error",
+			gRPCErr: New(codes.Internal).Message("dummy-msg").Err(),
+			want:    `{"code": -32603, "message": "dummy-msg", "data": {"status": "INTERNAL"}}`,
+		},
+		{
+			name:    "should project other errors to server error range",
+			gRPCErr: New(codes.NotFound).Message("dummy-msg").Err(),
+			want:    `{"code": -32005, "message": "dummy-msg", "data": {"status": "NOT_FOUND"}}`,
+		},
+		{
+			name:    "should map error which isn't a gRPC error",
+			gRPCErr: errors.New("dummy-err"),
+			want:    `{"code": -32002, "message": "` + defaultUnknownErrMsg + `", "data": {"status": "UNKNOWN"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+
+			// When
+			got := JSONRPCErrorFrom(tt.gRPCErr)
+
+			// Then
+			data, err := json.Marshal(got)
+			assert(err).IsNil()
+			assert(string(data)).IsJSONEqualTo(tt.want)
+		})
+	}
+}
+
+func TestGraphQLErrorFrom(t *testing.T) {
+	tests := []struct {
+		name    string
+		gRPCErr error
+		path    []interface{}
+		want    string
+	}{
+		{
+			name: "should project code name, reason and field violations into extensions",
+			gRPCErr: New(codes.InvalidArgument).
+				Message("dummy-msg").
+				ErrorInfo(&ErrorInfo{Reason: "DUMMY_REASON", Domain: "dummy-domain"}).
+				FieldViolations([]FieldViolation{{Field: "dummy-field", Description: "dummy-desc"}}).
+				Err(),
+			path: []interface{}{"dummyField", 1},
+			want: `{
+				"message": "dummy-msg",
+				"path": ["dummyField", 1],
+				"extensions": {
+					"code": "INVALID_ARGUMENT",
+					"reason": "DUMMY_REASON",
+					"fieldViolations": [{"field": "dummy-field", "description": "dummy-desc"}]
+				}
+			}`,
+		},
+		{
+			name:    "should leave out path when get nil path",
+			gRPCErr: New(codes.NotFound).Message("dummy-msg").Err(),
+			path:    nil,
+			want:    `{"message": "dummy-msg", "extensions": {"code": "NOT_FOUND"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+
+			// When
+			got := GraphQLErrorFrom(tt.gRPCErr, tt.path)
+
+			// Then
+			data, err := json.Marshal(got)
+			assert(err).IsNil()
+			assert(string(data)).IsJSONEqualTo(tt.want)
+		})
+	}
+}
+
+func TestProjectionsMaskingAndRedaction(t *testing.T) {
+	defer SetDefaultMasking(nil)
+	defer SetDefaultRedactionPolicy(nil)
+
+	// Given
+	assert := assert.New(t)
+	SetDefaultMasking(&Masking{NewIncidentID: func() string { return "dummy-incident-id" }})
+	SetDefaultRedactionPolicy(ExternalRedactionPolicy)
+	internal := New(codes.Internal).
+		Message("dummy-msg SELECT * FROM users failed on db-7.internal").
+		DebugInfo(&DebugInfo{StackEntries: []string{"dummy-stack-entry"}, Detail: "dummy-detail"}).
+		Err()
+	notFound := New(codes.NotFound).
+		Message("dummy-msg").
+		DebugInfo(&DebugInfo{Detail: "dummy-detail"}).
+		Err()
+
+	// When
+	jsonRPCInternal, _ := json.Marshal(JSONRPCErrorFrom(internal))
+	jsonRPCNotFound, _ := json.Marshal(JSONRPCErrorFrom(notFound))
+	graphQLInternal, _ := json.Marshal(GraphQLErrorFrom(internal, nil))
+
+	// Then
+	assert(string(jsonRPCInternal)).IsJSONEqualTo(`{
+		"code": -32603,
+		"message": "` + defaultInternalErrMsg + `",
+		"data": {
+			"status": "INTERNAL",
+			"details": [{"@type": "type.googleapis.com/google.rpc.RequestInfo", "requestId": "dummy-incident-id"}]
+		}
+	}`)
+	assert(string(jsonRPCNotFound)).IsJSONEqualTo(`{"code": -32005, "message": "dummy-msg", "data": {"status": "NOT_FOUND"}}`)
+	assert(string(graphQLInternal)).IsJSONEqualTo(`{"message": "` + defaultInternalErrMsg + `", "extensions": {"code": "INTERNAL"}}`)
+}
+
+func TestProjectionsNilError(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+
+	// When
+	jsonRPCErr, _ := json.Marshal(JSONRPCErrorFrom(nil))
+	graphQLErr, _ := json.Marshal(GraphQLErrorFrom(nil, nil))
+
+	// Then
+	assert(string(jsonRPCErr)).IsJSONEqualTo(`{"code": -32603, "message": "` + defaultInternalErrMsg + `", "data": {"status": "INTERNAL"}}`)
+	assert(string(graphQLErr)).IsJSONEqualTo(`{"message": "` + defaultInternalErrMsg + `", "extensions": {"code": "INTERNAL"}}`)
+}
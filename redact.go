@@ -0,0 +1,119 @@
+package grpcerr
+
+import (
+	"regexp"
+	"sync/atomic"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// RedactionPolicy describes which parts of a gRPC error are removed before it leaves the process.
+// The zero value removes nothing.
+//
+// Example of a policy which strips DebugInfo, drops ErrorInfo metadata keys containing "secret" or
+// "token" and keeps everything else, such as RequestInfo:
+//
+//	policy := &grpcerr.RedactionPolicy{
+//	    StripDetails:      []string{"google.rpc.DebugInfo"},
+//	    StripMetadataKeys: regexp.MustCompile(`secret|token`),
+//	}
+type RedactionPolicy struct {
+	// StripDetails holds the full names of the detail types which are removed, for example
+	// "google.rpc.DebugInfo". Details of other types are kept.
+	StripDetails []string
+	// StripMetadataKeys removes the ErrorInfo metadata entries whose keys match it. If nil, all
+	// entries are kept.
+	StripMetadataKeys *regexp.Regexp
+}
+
+// ExternalRedactionPolicy is a policy suitable for errors sent to external clients. It strips DebugInfo
+// and drops ErrorInfo metadata keys which contain "secret", "token" or "password", in any case.
+var ExternalRedactionPolicy = &RedactionPolicy{
+	StripDetails:      []string{"google.rpc.DebugInfo"},
+	StripMetadataKeys: regexp.MustCompile(`(?i)secret|token|password`),
+}
+
+// defaultRedactionPolicy holds the *RedactionPolicy set using SetDefaultRedactionPolicy.
+var defaultRedactionPolicy atomic.Value
+
+// SetDefaultRedactionPolicy sets the policy used by the HTTP response encoder and the server interceptors
+// when no other policy is given. It's typically set once per environment, for example to
+// ExternalRedactionPolicy in production. By default nothing is redacted.
+func SetDefaultRedactionPolicy(policy *RedactionPolicy) {
+	defaultRedactionPolicy.Store(policy)
+}
+
+// DefaultRedactionPolicy returns the policy set using SetDefaultRedactionPolicy, or nil if there isn't any.
+func DefaultRedactionPolicy() *RedactionPolicy {
+	policy, _ := defaultRedactionPolicy.Load().(*RedactionPolicy)
+	return policy
+}
+
+// Redact returns a copy of the status with the parts described by the policy removed. The status
+// itself is not modified. If the policy is nil, the status is returned as is.
+func (p *RedactionPolicy) Redact(st *status.Status) *status.Status {
+	if p == nil || st == nil {
+		return st
+	}
+
+	redacted := proto.Clone(st.Proto()).(*spb.Status)
+	details := make([]*anypb.Any, 0, len(redacted.Details))
+	for _, detail := range redacted.Details {
+		if p.strips(string(detail.MessageName())) {
+			continue
+		}
+		if p.StripMetadataKeys != nil && detail.MessageIs(&errdetails.ErrorInfo{}) {
+			redactedDetail, err := p.redactErrorInfo(detail)
+			if err != nil {
+				// Drops the detail rather than risking that it leaks
+				continue
+			}
+			detail = redactedDetail
+		}
+		details = append(details, detail)
+	}
+	redacted.Details = details
+
+	return status.FromProto(redacted)
+}
+
+// RedactError returns a gRPC error with the status of gRPCErr redacted according to the policy. The
+// outermost status of gRPCErr is redacted regardless of the lookup policy, since it's the one which
+// replaces the statuses it wraps, for example when gRPCErr was masked. The returned error has no cause,
+// so the unredacted status can't be found by unwrapping it. If gRPCErr is nil or the policy is nil,
+// gRPCErr is returned.
+func (p *RedactionPolicy) RedactError(gRPCErr error) error {
+	if p == nil || gRPCErr == nil {
+		return gRPCErr
+	}
+
+	return &Error{st: p.Redact(outermostStatusFrom(gRPCErr))}
+}
+
+func (p *RedactionPolicy) strips(name string) bool {
+	for _, stripped := range p.StripDetails {
+		if stripped == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RedactionPolicy) redactErrorInfo(detail *anypb.Any) (*anypb.Any, error) {
+	errorInfo := &errdetails.ErrorInfo{}
+	if err := detail.UnmarshalTo(errorInfo); err != nil {
+		return nil, err
+	}
+
+	for key := range errorInfo.Metadata {
+		if p.StripMetadataKeys.MatchString(key) {
+			delete(errorInfo.Metadata, key)
+		}
+	}
+
+	return anypb.New(errorInfo)
+}
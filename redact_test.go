@@ -0,0 +1,124 @@
+package grpcerr
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/tobbstr/testa/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRedactionPolicyRedact(t *testing.T) {
+	st, err := status.New(codes.Internal, "dummy-msg").WithDetails(
+		&errdetails.DebugInfo{Detail: "dummy-detail"},
+		&errdetails.ErrorInfo{Reason: "dummy-reason", Metadata: map[string]string{"apiToken": "dummy-token", "dummy-key": "dummy-value"}},
+		&errdetails.RequestInfo{RequestId: "dummy-request-id"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errorInfo := ErrorInfo{Reason: "dummy-reason", Metadata: map[string]string{"apiToken": "dummy-token", "dummy-key": "dummy-value"}}
+	errorInfoWithoutToken := ErrorInfo{Reason: "dummy-reason", Metadata: map[string]string{"dummy-key": "dummy-value"}}
+	allDetails := []string{"google.rpc.DebugInfo", "google.rpc.ErrorInfo", "google.rpc.RequestInfo"}
+	detailsWithoutDebugInfo := []string{"google.rpc.ErrorInfo", "google.rpc.RequestInfo"}
+
+	tests := []struct {
+		name          string
+		policy        *RedactionPolicy
+		wantDetails   []string
+		wantErrorInfo ErrorInfo
+	}{
+		{
+			name:          "should return status as is when get nil policy",
+			policy:        nil,
+			wantDetails:   allDetails,
+			wantErrorInfo: errorInfo,
+		},
+		{
+			name:          "should return equal status when get zero value policy",
+			policy:        &RedactionPolicy{},
+			wantDetails:   allDetails,
+			wantErrorInfo: errorInfo,
+		},
+		{
+			name:          "should strip details of given types",
+			policy:        &RedactionPolicy{StripDetails: []string{"google.rpc.DebugInfo"}},
+			wantDetails:   detailsWithoutDebugInfo,
+			wantErrorInfo: errorInfo,
+		},
+		{
+			name: "should strip details of given types and drop matching metadata keys",
+			policy: &RedactionPolicy{
+				StripDetails:      []string{"google.rpc.DebugInfo"},
+				StripMetadataKeys: regexp.MustCompile(`(?i)secret|token`),
+			},
+			wantDetails:   detailsWithoutDebugInfo,
+			wantErrorInfo: errorInfoWithoutToken,
+		},
+		{
+			name:          "should strip DebugInfo and drop token metadata key when get ExternalRedactionPolicy",
+			policy:        ExternalRedactionPolicy,
+			wantDetails:   detailsWithoutDebugInfo,
+			wantErrorInfo: errorInfoWithoutToken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+
+			// When
+			got := tt.policy.Redact(st)
+
+			// Then
+			gotDetails := make([]string, 0, len(got.Proto().Details))
+			for _, detail := range got.Proto().Details {
+				gotDetails = append(gotDetails, string(detail.MessageName()))
+			}
+			assert(got.Code()).Equals(codes.Internal)
+			assert(got.Message()).Equals("dummy-msg")
+			assert(gotDetails).Equals(tt.wantDetails)
+			assert(ErrorInfoFrom(got.Err())).Equals(tt.wantErrorInfo)
+			assert(len(st.Details())).Equals(3)
+		})
+	}
+}
+
+func TestRedactionPolicyRedactError(t *testing.T) {
+	defer SetLookupPolicy(LookupOutermost)
+
+	tests := []struct {
+		name   string
+		policy LookupPolicy
+	}{
+		{
+			name:   "should redact outermost status when lookup policy is outermost",
+			policy: LookupOutermost,
+		},
+		{
+			name:   "should redact outermost status when lookup policy is innermost",
+			policy: LookupInnermost,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			SetLookupPolicy(tt.policy)
+			cause := New(codes.Internal).Message("dummy-cause-msg").DebugInfo(&DebugInfo{Detail: "dummy-cause-detail"}).Err()
+			gRPCErr := New(codes.Internal).DebugInfo(&DebugInfo{Detail: "dummy-detail"}).Cause(cause).Err()
+
+			// When
+			got := ExternalRedactionPolicy.RedactError(gRPCErr)
+
+			// Then
+			assert(Code(got)).Equals(codes.Internal)
+			assert(Message(got)).Equals(defaultInternalErrMsg)
+			assert(DebugInfoFrom(got)).Equals(DebugInfo{})
+			assert(errors.Unwrap(got)).IsNil()
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package grpcerr
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"google.golang.org/grpc/status"
+)
+
+const (
+	contentTypeHTML = "text/html; charset=utf-8"
+	contentTypeText = "text/plain; charset=utf-8"
+)
+
+// ErrorPage is the data passed to the template used by AsHTML.
+type ErrorPage struct {
+	// The HTTP status code, for example 404.
+	StatusCode int
+	// The HTTP status text, for example "Not Found".
+	StatusText string
+	// The name of the gRPC code, for example "NotFound".
+	Code            string
+	Message         string
+	FieldViolations []FieldViolation
+	HelpLinks       []HelpLink
+	RequestID       string
+}
+
+// DefaultHTMLTemplate is the template used by AsHTML when no other template is given using HTMLTemplate().
+var DefaultHTMLTemplate = template.Must(template.New("errorPage").Parse(`<!DOCTYPE html>
+<html>
+<head><title>{{.StatusCode}} {{.StatusText}}</title></head>
+<body>
+<h1>{{.StatusCode}} {{.StatusText}}</h1>
+<p>{{.Message}}</p>
+{{if .FieldViolations}}<h2>Field violations</h2>
+<ul>
+{{range .FieldViolations}}<li><code>{{.Field}}</code>: {{.Description}}</li>
+{{end}}</ul>
+{{end}}{{if .HelpLinks}}<h2>Help</h2>
+<ul>
+{{range .HelpLinks}}<li><a href="{{.URL}}">{{if .Description}}{{.Description}}{{else}}{{.URL}}{{end}}</a></li>
+{{end}}</ul>
+{{end}}{{if .RequestID}}<p>Request ID: <code>{{.RequestID}}</code></p>
+{{end}}</body>
+</html>
+`))
+
+// HTMLTemplate sets the template used by AsHTML, which overrides DefaultHTMLTemplate. The template is
+// executed with an ErrorPage.
+func (f *httpResponseEncoder) HTMLTemplate(tmpl *template.Template) *httpResponseEncoder {
+	f.htmlTemplate = tmpl
+	return f
+}
+
+// AsHTML renders the gRPC error as an HTML error page, listing its field violations, help links and
+// request ID, and writes it to the http.ResponseWriter. It's meant for endpoints which are visited using
+// browsers. The page can be customized using HTMLTemplate().
+// If the error isn't a gRPC error, it's mapped to one using FromError.
+// If an error occurs it is returned, otherwise it returns nil.
+func (f *httpResponseEncoder) AsHTML() error {
+	return f.Encode(htmlEncoder{tmpl: f.htmlTemplate})
+}
+
+// htmlEncoder is the Encoder used by AsHTML. A nil template means DefaultHTMLTemplate.
+type htmlEncoder struct {
+	tmpl *template.Template
+}
+
+func (htmlEncoder) ContentType() string {
+	return contentTypeHTML
+}
+
+func (e htmlEncoder) Encode(st *status.Status, httpStatusCode int, _ http.Header) ([]byte, error) {
+	tmpl := e.tmpl
+	if tmpl == nil {
+		tmpl = DefaultHTMLTemplate
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, errorPageFrom(st, httpStatusCode)); err != nil {
+		return nil, fmt.Errorf("could not render HTML from gRPC status: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// AsText renders the gRPC error as a human-readable plain text summary, listing its field violations,
+// help links and request ID, and writes it to the http.ResponseWriter. It's meant for endpoints which
+// are called using curl and the like. For example:
+//
+//	400 Bad Request
+//	InvalidArgument: The email address is invalid.
+//
+//	Field violations:
+//	  email: must contain an @
+//
+//	Request ID: abc123
+//
+// If the error isn't a gRPC error, it's mapped to one using FromError.
+// If an error occurs it is returned, otherwise it returns nil.
+func (f *httpResponseEncoder) AsText() error {
+	return f.Encode(textEncoder{})
+}
+
+// textEncoder is the Encoder used by AsText.
+type textEncoder struct{}
+
+func (textEncoder) ContentType() string {
+	return contentTypeText
+}
+
+func (textEncoder) Encode(st *status.Status, httpStatusCode int, _ http.Header) ([]byte, error) {
+	return textFrom(errorPageFrom(st, httpStatusCode)), nil
+}
+
+func errorPageFrom(st *status.Status, httpStatusCode int) ErrorPage {
+	gRPCErr := st.Err()
+
+	page := ErrorPage{
+		StatusCode: httpStatusCode,
+		StatusText: http.StatusText(httpStatusCode),
+		Code:       st.Code().String(),
+		Message:    st.Message(),
+		RequestID:  RequestInfoFrom(gRPCErr).RequestID,
+	}
+	if fieldViolations := FieldViolationsFrom(gRPCErr); len(fieldViolations) > 0 {
+		page.FieldViolations = fieldViolations
+	}
+	if helpLinks := HelpLinksFrom(gRPCErr); len(helpLinks) > 0 {
+		page.HelpLinks = helpLinks
+	}
+
+	return page
+}
+
+func textFrom(page ErrorPage) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%d %s\n", page.StatusCode, page.StatusText)
+	fmt.Fprintf(&buf, "%s: %s\n", page.Code, page.Message)
+
+	if len(page.FieldViolations) > 0 {
+		buf.WriteString("\nField violations:\n")
+		for _, violation := range page.FieldViolations {
+			fmt.Fprintf(&buf, "  %s: %s\n", violation.Field, violation.Description)
+		}
+	}
+	if len(page.HelpLinks) > 0 {
+		buf.WriteString("\nHelp:\n")
+		for _, link := range page.HelpLinks {
+			if link.Description == "" {
+				fmt.Fprintf(&buf, "  %s\n", link.URL)
+				continue
+			}
+			fmt.Fprintf(&buf, "  %s: %s\n", link.Description, link.URL)
+		}
+	}
+	if page.RequestID != "" {
+		fmt.Fprintf(&buf, "\nRequest ID: %s\n", page.RequestID)
+	}
+
+	return buf.Bytes()
+}
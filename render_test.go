@@ -0,0 +1,127 @@
+package grpcerr
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tobbstr/testa/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestHttpResponseEncodeWriteAsHTML(t *testing.T) {
+	gRPCErr := New(codes.InvalidArgument).
+		Message("<dummy-msg>").
+		FieldViolations([]FieldViolation{{Field: "dummy-field", Description: "dummy-desc"}}).
+		Help([]HelpLink{{Description: "dummy-help-desc", URL: "https://dummy.example.com/help"}}).
+		RequestInfo(&RequestInfo{RequestID: "dummy-request-id"}).
+		Err()
+
+	tests := []struct {
+		name         string
+		tmpl         *template.Template
+		wantContains []string
+	}{
+		{
+			name: "Should render default template",
+			tmpl: nil,
+			wantContains: []string{
+				"<h1>400 Bad Request</h1>",
+				"<p>&lt;dummy-msg&gt;</p>",
+				"<li><code>dummy-field</code>: dummy-desc</li>",
+				`<li><a href="https://dummy.example.com/help">dummy-help-desc</a></li>`,
+				"<p>Request ID: <code>dummy-request-id</code></p>",
+			},
+		},
+		{
+			name:         "Should render given template",
+			tmpl:         template.Must(template.New("dummy").Parse(`<p>{{.Code}} {{.RequestID}}</p>`)),
+			wantContains: []string{"<p>InvalidArgument dummy-request-id</p>"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			w := httptest.NewRecorder()
+
+			// When
+			err := NewHttpResponseEncodeWriter(w)(gRPCErr).HTMLTemplate(tt.tmpl).AsHTML()
+
+			// Then
+			assert(err).IsNil()
+			assert(w.Code).Equals(http.StatusBadRequest)
+			assert(w.Header().Get("Content-Type")).Equals("text/html; charset=utf-8")
+			for _, want := range tt.wantContains {
+				assert(strings.Contains(w.Body.String(), want)).IsTrue()
+			}
+		})
+	}
+}
+
+func TestHttpResponseEncodeWriteAsHTMLTemplateError(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+	w := httptest.NewRecorder()
+	tmpl := template.Must(template.New("dummy").Parse(`{{.DummyField}}`))
+
+	// When
+	err := NewHttpResponseEncodeWriter(w)(New(codes.NotFound).Err()).HTMLTemplate(tmpl).AsHTML()
+
+	// Then
+	assert(err).IsNotNil()
+	assert(w.Code).Equals(http.StatusInternalServerError)
+}
+
+func TestHttpResponseEncodeWriteAsText(t *testing.T) {
+	tests := []struct {
+		name    string
+		gRPCErr error
+		mapping *HTTPStatusMapping
+		want    string
+	}{
+		{
+			name: "Should render field violations, help links and request ID",
+			gRPCErr: New(codes.InvalidArgument).
+				Message("dummy-msg").
+				FieldViolations([]FieldViolation{{Field: "dummy-field", Description: "dummy-desc"}}).
+				Help([]HelpLink{{Description: "dummy-help-desc", URL: "https://dummy.example.com/help"}, {URL: "https://dummy.example.com/other"}}).
+				RequestInfo(&RequestInfo{RequestID: "dummy-request-id"}).
+				Err(),
+			want: "400 Bad Request\n" +
+				"InvalidArgument: dummy-msg\n" +
+				"\n" +
+				"Field violations:\n" +
+				"  dummy-field: dummy-desc\n" +
+				"\n" +
+				"Help:\n" +
+				"  dummy-help-desc: https://dummy.example.com/help\n" +
+				"  https://dummy.example.com/other\n" +
+				"\n" +
+				"Request ID: dummy-request-id\n",
+		},
+		{
+			name:    "Should use status mapping",
+			gRPCErr: New(codes.InvalidArgument).Message("dummy-msg").Err(),
+			mapping: &HTTPStatusMapping{Codes: map[codes.Code]int{codes.InvalidArgument: http.StatusUnprocessableEntity}},
+			want:    "422 Unprocessable Entity\nInvalidArgument: dummy-msg\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			w := httptest.NewRecorder()
+
+			// When
+			err := NewHttpResponseEncodeWriter(w)(tt.gRPCErr).MapStatus(tt.mapping).AsText()
+
+			// Then
+			assert(err).IsNil()
+			assert(w.Header().Get("Content-Type")).Equals("text/plain; charset=utf-8")
+			assert(w.Body.String()).Equals(tt.want)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package grpcerr
+
+import "sync"
+
+// ring is a fixed size buffer which replaces its oldest item when it's full. It's safe for concurrent use.
+type ring struct {
+	mu    sync.RWMutex
+	buf   []interface{}
+	next  int
+	count int
+}
+
+func newRing(size int) *ring {
+	if size < 1 {
+		size = 1
+	}
+	return &ring{buf: make([]interface{}, size)}
+}
+
+// add stores the item, replacing the oldest one if the ring is full.
+func (r *ring) add(item interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.buf[r.next] = item
+	r.next = (r.next + 1) % len(r.buf)
+	if r.count < len(r.buf) {
+		r.count++
+	}
+}
+
+// items returns the items in the ring, the most recent first.
+func (r *ring) items() []interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	items := make([]interface{}, 0, r.count)
+	for i := 1; i <= r.count; i++ {
+		items = append(items, r.buf[(r.next-i+len(r.buf))%len(r.buf)])
+	}
+
+	return items
+}
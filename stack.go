@@ -0,0 +1,174 @@
+package grpcerr
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strings"
+	"sync/atomic"
+
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	defaultStackDepth = 32
+	// maxStackFrames is the maximum number of frames captured before they're filtered.
+	maxStackFrames = 128
+)
+
+// FrameFilter reports whether a stack frame should be dropped from captured stack traces.
+type FrameFilter func(frame runtime.Frame) bool
+
+// DropPackages returns a FrameFilter which drops frames of functions in any of the packages,
+// including their sub-packages. For example, "net/http" drops frames in both net/http and
+// net/http/httputil.
+func DropPackages(pkgs ...string) FrameFilter {
+	return func(frame runtime.Frame) bool {
+		for _, pkg := range pkgs {
+			if strings.HasPrefix(frame.Function, pkg+".") || strings.HasPrefix(frame.Function, pkg+"/") {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// DefaultFrameFilters drops frames of the Go runtime, the gRPC framework and the net/http package,
+// which rarely help finding the cause of an error.
+var DefaultFrameFilters = []FrameFilter{
+	DropPackages("runtime", "google.golang.org/grpc", "net/http"),
+}
+
+// stackCapture holds the configuration of automatic stack capture.
+type stackCapture struct {
+	depth      int
+	sampleRate float64
+	filters    []FrameFilter
+}
+
+// StackCaptureOption is an option function used to configure automatic stack capture.
+type StackCaptureOption func(c *stackCapture)
+
+// WithStackDepth sets the maximum number of stack entries captured. The default is 32, which is also used
+// if depth is 0 or less.
+func WithStackDepth(depth int) StackCaptureOption {
+	return func(c *stackCapture) {
+		if depth <= 0 {
+			depth = defaultStackDepth
+		}
+		c.depth = depth
+	}
+}
+
+// WithSampleRate sets the fraction of errors, between 0 and 1, for which the stack is captured. For
+// example 0.1 captures the stack for one in ten errors on average. The default is 1.
+func WithSampleRate(rate float64) StackCaptureOption {
+	return func(c *stackCapture) {
+		c.sampleRate = rate
+	}
+}
+
+// WithFrameFilters sets the filters used to drop frames from captured stacks. They replace
+// DefaultFrameFilters.
+func WithFrameFilters(filters ...FrameFilter) StackCaptureOption {
+	return func(c *stackCapture) {
+		c.filters = filters
+	}
+}
+
+// currentStackCapture holds the *stackCapture in use, or a nil *stackCapture if disabled.
+var currentStackCapture atomic.Value
+
+// EnableStackCapture enables automatic capture of the stack into DebugInfo.StackEntries when using
+// NewInternal, NewUnknown, NewDataLoss and AddDebugInfo, and when using Builder.Err and Errorf with the
+// Internal, Unknown or DataLoss code. All but AddDebugInfo add a DebugInfo if there's none, while
+// AddDebugInfo only fills in a DebugInfo without stack entries. Stack entries which are set explicitly
+// are never overwritten.
+func EnableStackCapture(opts ...StackCaptureOption) {
+	c := &stackCapture{
+		depth:      defaultStackDepth,
+		sampleRate: 1,
+		filters:    DefaultFrameFilters,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	currentStackCapture.Store(c)
+}
+
+// DisableStackCapture disables automatic stack capture. It's disabled by default.
+func DisableStackCapture() {
+	currentStackCapture.Store((*stackCapture)(nil))
+}
+
+// debugInfoWithStack returns a copy of debugInfo with the stack captured into its StackEntries, if
+// stack capture is enabled and sampled, and it has no stack entries. Otherwise debugInfo is returned.
+// If allowNil is false and debugInfo is nil, nil is returned.
+//
+// It must be called directly from the exported function, for the captured stack to start at its caller.
+func debugInfoWithStack(debugInfo *DebugInfo, allowNil bool) *DebugInfo {
+	return debugInfoWithStackSkip(debugInfo, allowNil, 1)
+}
+
+// debugInfoWithStackSkip is like debugInfoWithStack, where skip is the number of frames between it and
+// the exported function.
+func debugInfoWithStackSkip(debugInfo *DebugInfo, allowNil bool, skip int) *DebugInfo {
+	if debugInfo == nil && !allowNil {
+		return nil
+	}
+	if debugInfo != nil && len(debugInfo.StackEntries) > 0 {
+		return debugInfo
+	}
+
+	c, _ := currentStackCapture.Load().(*stackCapture)
+	if c == nil || c.sampleRate <= 0 || rand.Float64() >= c.sampleRate {
+		return debugInfo
+	}
+
+	withStack := &DebugInfo{}
+	if debugInfo != nil {
+		*withStack = *debugInfo
+	}
+	// Skips runtime.Callers, captureStack, debugInfoWithStackSkip, the frames in between and the exported
+	// function
+	withStack.StackEntries = c.captureStack(4 + skip)
+
+	return withStack
+}
+
+// capturesStack reports whether the stack is captured for errors with the code, which is the case for
+// server faults.
+func capturesStack(code codes.Code) bool {
+	return code == codes.Internal || code == codes.Unknown || code == codes.DataLoss
+}
+
+// captureStack returns the filtered stack entries of the calling goroutine, skipping the given
+// number of frames.
+func (c *stackCapture) captureStack(skip int) []string {
+	pcs := make([]uintptr, maxStackFrames)
+	n := runtime.Callers(skip, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	entries := make([]string, 0, c.depth)
+	for len(entries) < c.depth {
+		frame, more := frames.Next()
+		if !c.isFiltered(frame) {
+			entries = append(entries, fmt.Sprintf("%s (%s:%d)", frame.Function, frame.File, frame.Line))
+		}
+		if !more {
+			break
+		}
+	}
+
+	return entries
+}
+
+func (c *stackCapture) isFiltered(frame runtime.Frame) bool {
+	for _, filter := range c.filters {
+		if filter(frame) {
+			return true
+		}
+	}
+	return false
+}
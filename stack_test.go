@@ -0,0 +1,212 @@
+package grpcerr
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/tobbstr/testa/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestEnableStackCapture(t *testing.T) {
+	defer DisableStackCapture()
+
+	tests := []struct {
+		name            string
+		opts            []StackCaptureOption
+		newGRPCErr      func() (error, error)
+		wantEntries     int
+		wantFirstPrefix string
+	}{
+		{
+			name: "should capture stack starting at caller when get NewInternal without DebugInfo",
+			opts: nil,
+			newGRPCErr: func() (error, error) {
+				return NewInternal("dummy-msg", nil)
+			},
+			wantEntries:     -1,
+			wantFirstPrefix: "github.com/tobbstr/grpcerr.TestEnableStackCapture.func",
+		},
+		{
+			name: "should capture stack and keep detail when get NewUnknown with DebugInfo without stack entries",
+			opts: nil,
+			newGRPCErr: func() (error, error) {
+				return NewUnknown("dummy-msg", &DebugInfo{Detail: "dummy-detail"})
+			},
+			wantEntries:     -1,
+			wantFirstPrefix: "github.com/tobbstr/grpcerr.TestEnableStackCapture.func",
+		},
+		{
+			name: "should capture stack when get NewDataLoss",
+			opts: nil,
+			newGRPCErr: func() (error, error) {
+				return NewDataLoss("dummy-msg", nil)
+			},
+			wantEntries:     -1,
+			wantFirstPrefix: "github.com/tobbstr/grpcerr.TestEnableStackCapture.func",
+		},
+		{
+			name: "should capture stack when get AddDebugInfo with DebugInfo without stack entries",
+			opts: nil,
+			newGRPCErr: func() (error, error) {
+				return AddDebugInfo(NewUnimplemented("dummy-msg"), &DebugInfo{})
+			},
+			wantEntries:     -1,
+			wantFirstPrefix: "github.com/tobbstr/grpcerr.TestEnableStackCapture.func",
+		},
+		{
+			name: "should capture stack when get builder with Internal code",
+			opts: nil,
+			newGRPCErr: func() (error, error) {
+				return New(codes.Internal).Err(), nil
+			},
+			wantEntries:     -1,
+			wantFirstPrefix: "github.com/tobbstr/grpcerr.TestEnableStackCapture.func",
+		},
+		{
+			name: "should capture stack and keep detail when get builder with DebugInfo without stack entries",
+			opts: nil,
+			newGRPCErr: func() (error, error) {
+				return New(codes.DataLoss).DebugInfo(&DebugInfo{Detail: "dummy-detail"}).Err(), nil
+			},
+			wantEntries:     -1,
+			wantFirstPrefix: "github.com/tobbstr/grpcerr.TestEnableStackCapture.func",
+		},
+		{
+			name: "should capture stack when get Errorf with Unknown code",
+			opts: nil,
+			newGRPCErr: func() (error, error) {
+				return Errorf(codes.Unknown, "dummy-msg: %w", errors.New("dummy-cause")), nil
+			},
+			wantEntries:     -1,
+			wantFirstPrefix: "github.com/tobbstr/grpcerr.TestEnableStackCapture.func",
+		},
+		{
+			name: "should not capture stack when get builder with client fault code",
+			opts: nil,
+			newGRPCErr: func() (error, error) {
+				return New(codes.NotFound).Err(), nil
+			},
+			wantEntries:     0,
+			wantFirstPrefix: "",
+		},
+		{
+			name: "should not overwrite explicit stack entries when get builder",
+			opts: nil,
+			newGRPCErr: func() (error, error) {
+				return New(codes.Internal).DebugInfo(&DebugInfo{StackEntries: []string{"dummy-stack-entry"}}).Err(), nil
+			},
+			wantEntries:     1,
+			wantFirstPrefix: "dummy-stack-entry",
+		},
+		{
+			name: "should not overwrite explicit stack entries",
+			opts: nil,
+			newGRPCErr: func() (error, error) {
+				return NewInternal("dummy-msg", &DebugInfo{StackEntries: []string{"dummy-stack-entry"}})
+			},
+			wantEntries:     1,
+			wantFirstPrefix: "dummy-stack-entry",
+		},
+		{
+			name: "should limit number of stack entries to depth",
+			opts: []StackCaptureOption{WithStackDepth(1)},
+			newGRPCErr: func() (error, error) {
+				return NewInternal("dummy-msg", nil)
+			},
+			wantEntries:     1,
+			wantFirstPrefix: "github.com/tobbstr/grpcerr.TestEnableStackCapture.func",
+		},
+		{
+			name: "should capture stack using default depth when get negative depth",
+			opts: []StackCaptureOption{WithStackDepth(-1)},
+			newGRPCErr: func() (error, error) {
+				return NewInternal("dummy-msg", nil)
+			},
+			wantEntries:     -1,
+			wantFirstPrefix: "github.com/tobbstr/grpcerr.TestEnableStackCapture.func",
+		},
+		{
+			name: "should drop filtered frames",
+			opts: []StackCaptureOption{WithFrameFilters(DropPackages("github.com/tobbstr/grpcerr"))},
+			newGRPCErr: func() (error, error) {
+				return NewInternal("dummy-msg", nil)
+			},
+			wantEntries:     -1,
+			wantFirstPrefix: "testing.tRunner",
+		},
+		{
+			name: "should not capture stack when get sample rate 0",
+			opts: []StackCaptureOption{WithSampleRate(0)},
+			newGRPCErr: func() (error, error) {
+				return NewInternal("dummy-msg", nil)
+			},
+			wantEntries:     0,
+			wantFirstPrefix: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			EnableStackCapture(tt.opts...)
+
+			// When
+			gRPCErr, err := tt.newGRPCErr()
+
+			// Then
+			assert(err).IsNil()
+			entries := DebugInfoFrom(gRPCErr).StackEntries
+			if tt.wantEntries >= 0 {
+				assert(len(entries)).Equals(tt.wantEntries)
+			} else {
+				assert(len(entries) > 0).IsTrue()
+			}
+			if len(entries) > 0 {
+				assert(strings.HasPrefix(entries[0], tt.wantFirstPrefix)).IsTrue()
+			}
+		})
+	}
+}
+
+func TestStackCaptureDisabledByDefault(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+
+	// When
+	gRPCErr, err := NewInternal("dummy-msg", nil)
+
+	// Then
+	assert(err).IsNil()
+	assert(DebugInfoFrom(gRPCErr)).Equals(DebugInfo{})
+}
+
+func TestDefaultFrameFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		function string
+		want     bool
+	}{
+		{name: "should drop runtime frames", function: "runtime.goexit", want: true},
+		{name: "should drop grpc frames", function: "google.golang.org/grpc.(*Server).processUnaryRPC", want: true},
+		{name: "should drop net/http frames", function: "net/http.(*conn).serve", want: true},
+		{name: "should drop net/http sub-package frames", function: "net/http/httputil.(*ReverseProxy).ServeHTTP", want: true},
+		{name: "should keep application frames", function: "main.handler", want: false},
+		{name: "should keep frames of packages with same prefix", function: "runtimeutil.Do", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			frame := runtime.Frame{Function: tt.function}
+
+			// When
+			got := DefaultFrameFilters[0](frame)
+
+			// Then
+			assert(got).Equals(tt.want)
+		})
+	}
+}
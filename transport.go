@@ -0,0 +1,79 @@
+package grpcerr
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+)
+
+// maxDrainSize is the maximum number of bytes discarded from the body of an HTTP error response before
+// it's closed. Draining allows the connection to be reused, while not draining huge bodies.
+const maxDrainSize = 64 << 10
+
+// transportMappers map transport failures caused by cancellation or timeouts. All other transport
+// failures, such as DNS lookup failures and refused connections, are mapped to Unavailable.
+var transportMappers = []Mapper{
+	SentinelMapper(context.Canceled, codes.Canceled),
+	SentinelMapper(context.DeadlineExceeded, codes.DeadlineExceeded),
+	netTimeoutMapper,
+}
+
+// Transport is an http.RoundTripper which converts every 4xx and 5xx HTTP response into a gRPC error
+// using FromHTTPResponse, so that HTTP clients can switch on grpcerr.Code() like gRPC clients do. Other
+// responses are returned as is, which allows the http.Client to follow redirects and callers to handle
+// 304 Not Modified responses to conditional requests.
+// Transport failures are converted into DeadlineExceeded errors if caused by timeouts, into Canceled
+// errors if the request was canceled, and into Unavailable errors otherwise.
+//
+// The body of a 4xx or 5xx HTTP response is drained and closed, which allows the connection to be reused.
+// Since the http.Client wraps errors returned by its RoundTripper, the gRPC error must be obtained using
+// grpcerr.Code(), grpcerr.StatusFrom() or errors.As().
+//
+// Example:
+//
+//	client := &http.Client{Transport: &grpcerr.Transport{}}
+//	resp, err := client.Get(url)
+//	if grpcerr.Code(err) == codes.NotFound {
+//		...
+//	}
+type Transport struct {
+	// The RoundTripper used to make the requests. If nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base().RoundTrip(req)
+	if err != nil {
+		return nil, transportErrorFrom(err)
+	}
+	if resp.StatusCode < 400 {
+		return resp, nil
+	}
+
+	gRPCErr := FromHTTPResponse(resp)
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainSize))
+	resp.Body.Close()
+
+	return nil, gRPCErr
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+// transportErrorFrom maps a transport failure to a gRPC error, whose cause is err.
+func transportErrorFrom(err error) error {
+	for _, mapper := range transportMappers {
+		if gRPCErr, ok := mapper(err); ok {
+			return gRPCErr
+		}
+	}
+
+	return New(codes.Unavailable).Cause(err).Err()
+}
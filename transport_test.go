@@ -0,0 +1,185 @@
+package grpcerr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tobbstr/testa/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.Write([]byte("dummy-body"))
+		case "/redirect":
+			http.Redirect(w, r, "/ok", http.StatusFound)
+		case "/not-modified":
+			w.WriteHeader(http.StatusNotModified)
+		case "/not-found":
+			NewHttpResponseEncodeWriter(w)(New(codes.NotFound).Message("dummy-msg").Err()).AsJSON()
+		case "/unavailable":
+			http.Error(w, "dummy-body", http.StatusServiceUnavailable)
+		case "/slow":
+			time.Sleep(100 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+	closedServer := httptest.NewServer(http.NotFoundHandler())
+	closedServer.Close()
+
+	tests := []struct {
+		name           string
+		url            string
+		timeout        time.Duration
+		wantCode       codes.Code
+		wantMsg        string
+		wantStatusCode int
+	}{
+		{
+			name:           "should return response when get 2xx response",
+			url:            server.URL + "/ok",
+			wantCode:       codes.OK,
+			wantMsg:        "",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "should follow redirect when get 3xx response",
+			url:            server.URL + "/redirect",
+			wantCode:       codes.OK,
+			wantMsg:        "",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "should return response when get not modified response",
+			url:            server.URL + "/not-modified",
+			wantCode:       codes.OK,
+			wantMsg:        "",
+			wantStatusCode: http.StatusNotModified,
+		},
+		{
+			name:     "should return gRPC error held by response when get response encoded using AsJSON",
+			url:      server.URL + "/not-found",
+			wantCode: codes.NotFound,
+			wantMsg:  "dummy-msg",
+		},
+		{
+			name:     "should derive gRPC error from HTTP status code when get plain text response",
+			url:      server.URL + "/unavailable",
+			wantCode: codes.Unavailable,
+			wantMsg:  "503 Service Unavailable",
+		},
+		{
+			name:     "should return DeadlineExceeded when request times out",
+			url:      server.URL + "/slow",
+			timeout:  10 * time.Millisecond,
+			wantCode: codes.DeadlineExceeded,
+			wantMsg:  defaultDeadlineExceededErrMsg,
+		},
+		{
+			name:     "should return Unavailable when connection is refused",
+			url:      closedServer.URL,
+			wantCode: codes.Unavailable,
+			wantMsg:  defaultUnavailableErrMsg,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			client := &http.Client{Transport: &Transport{}}
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// When
+			resp, err := client.Do(req)
+
+			// Then
+			if resp != nil {
+				resp.Body.Close()
+			}
+			assert(Code(err)).Equals(tt.wantCode)
+			assert(Message(err)).Equals(tt.wantMsg)
+			assert(resp == nil).Equals(tt.wantCode != codes.OK)
+			if resp != nil {
+				assert(resp.StatusCode).Equals(tt.wantStatusCode)
+			}
+		})
+	}
+}
+
+func TestTransportDrainsAndClosesBody(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+	body := &dummyBody{Reader: strings.NewReader("dummy-body" + strings.Repeat(" ", maxErrorBodySize))}
+	transport := &Transport{Base: dummyRoundTripper(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found", Body: body}, nil
+	})}
+
+	// When
+	resp, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// Then
+	assert(resp == nil).IsTrue()
+	assert(Code(err)).Equals(codes.NotFound)
+	assert(body.Len()).Equals(0)
+	assert(body.closed).IsTrue()
+}
+
+func TestTransportMapsTransportFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "should return Canceled when request is canceled", err: context.Canceled, wantCode: codes.Canceled},
+		{name: "should return DeadlineExceeded when get net timeout", err: dummyTimeoutError{}, wantCode: codes.DeadlineExceeded},
+		{name: "should return Unavailable when get other error", err: errors.New("dummy-err"), wantCode: codes.Unavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			transport := &Transport{Base: dummyRoundTripper(func(req *http.Request) (*http.Response, error) {
+				return nil, tt.err
+			})}
+
+			// When
+			_, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "/", nil))
+
+			// Then
+			assert(Code(err)).Equals(tt.wantCode)
+			assert(errors.Is(err, tt.err)).IsTrue()
+		})
+	}
+}
+
+type dummyRoundTripper func(req *http.Request) (*http.Response, error)
+
+func (f dummyRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+type dummyBody struct {
+	*strings.Reader
+	closed bool
+}
+
+func (b *dummyBody) Close() error {
+	b.closed = true
+	return nil
+}
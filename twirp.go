@@ -0,0 +1,179 @@
+package grpcerr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// twirpError is the JSON error of the Twirp protocol.
+type twirpError struct {
+	Code string            `json:"code"`
+	Msg  string            `json:"msg"`
+	Meta map[string]string `json:"meta,omitempty"`
+}
+
+// twirpCodes holds the Twirp codes of the gRPC codes.
+var twirpCodes = map[codes.Code]string{
+	codes.Canceled:           "canceled",
+	codes.Unknown:            "unknown",
+	codes.InvalidArgument:    "invalid_argument",
+	codes.DeadlineExceeded:   "deadline_exceeded",
+	codes.NotFound:           "not_found",
+	codes.AlreadyExists:      "already_exists",
+	codes.PermissionDenied:   "permission_denied",
+	codes.ResourceExhausted:  "resource_exhausted",
+	codes.FailedPrecondition: "failed_precondition",
+	codes.Aborted:            "aborted",
+	codes.OutOfRange:         "out_of_range",
+	codes.Unimplemented:      "unimplemented",
+	codes.Internal:           "internal",
+	codes.Unavailable:        "unavailable",
+	codes.DataLoss:           "dataloss",
+	codes.Unauthenticated:    "unauthenticated",
+}
+
+// gRPCCodesFromTwirp holds the gRPC codes of the Twirp codes. Twirp's malformed and bad_route codes have
+// no gRPC counterpart, so they're mapped to the closest gRPC codes.
+var gRPCCodesFromTwirp = map[string]codes.Code{
+	"canceled":            codes.Canceled,
+	"unknown":             codes.Unknown,
+	"invalid_argument":    codes.InvalidArgument,
+	"malformed":           codes.InvalidArgument,
+	"deadline_exceeded":   codes.DeadlineExceeded,
+	"not_found":           codes.NotFound,
+	"bad_route":           codes.Unimplemented,
+	"already_exists":      codes.AlreadyExists,
+	"permission_denied":   codes.PermissionDenied,
+	"unauthenticated":     codes.Unauthenticated,
+	"resource_exhausted":  codes.ResourceExhausted,
+	"failed_precondition": codes.FailedPrecondition,
+	"aborted":             codes.Aborted,
+	"out_of_range":        codes.OutOfRange,
+	"unimplemented":       codes.Unimplemented,
+	"internal":            codes.Internal,
+	"unavailable":         codes.Unavailable,
+	"dataloss":            codes.DataLoss,
+}
+
+// AsTwirpJSON encodes the gRPC error as a JSON error of the Twirp protocol and writes it to the
+// http.ResponseWriter. For example:
+//
+//	{"code": "not_found", "msg": "user not found", "meta": {"reason": "USER_NOT_FOUND", "domain": "example.com"}}
+//
+// The ErrorInfo's reason, domain and metadata are flattened into the meta member, where the reason and
+// domain take precedence over metadata with the same keys. Other details are left out.
+// The default HTTP status codes are the ones of the Twirp protocol, for example 412 for FailedPrecondition.
+// If the error isn't a gRPC error, it's mapped to one using FromError.
+// If an error occurs it is returned, otherwise it returns nil.
+func (f *httpResponseEncoder) AsTwirpJSON() error {
+	return f.Encode(twirpJSONEncoder{})
+}
+
+// twirpJSONEncoder is the Encoder used by AsTwirpJSON.
+type twirpJSONEncoder struct{}
+
+func (twirpJSONEncoder) ContentType() string {
+	return contentTypeJSON
+}
+
+func (twirpJSONEncoder) Encode(st *status.Status, _ int, _ http.Header) ([]byte, error) {
+	data, err := json.Marshal(twirpErrorFrom(st))
+	if err != nil {
+		return nil, fmt.Errorf("could not get Twirp error as bytes from gRPC status: %w", err)
+	}
+	return data, nil
+}
+
+func (twirpJSONEncoder) defaultHTTPStatusCode(st *status.Status) int {
+	return twirpHTTPStatusCodeFrom(st)
+}
+
+func twirpErrorFrom(st *status.Status) twirpError {
+	code, ok := twirpCodes[st.Code()]
+	if !ok {
+		code = "unknown"
+	}
+	twirpErr := twirpError{Code: code, Msg: st.Message()}
+
+	if errorInfo, ok := errorInfoDetailsFrom(st); ok {
+		twirpErr.Meta = make(map[string]string, len(errorInfo.Metadata)+2)
+		for k, v := range errorInfo.Metadata {
+			twirpErr.Meta[k] = v
+		}
+		if errorInfo.Reason != "" {
+			twirpErr.Meta["reason"] = errorInfo.Reason
+		}
+		if errorInfo.Domain != "" {
+			twirpErr.Meta["domain"] = errorInfo.Domain
+		}
+	}
+
+	return twirpErr
+}
+
+// twirpHTTPStatusCodeFrom returns the HTTP status code of the Twirp protocol for the status.
+func twirpHTTPStatusCodeFrom(st *status.Status) int {
+	switch st.Code() {
+	case codes.Canceled, codes.DeadlineExceeded:
+		return http.StatusRequestTimeout
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	}
+
+	return httpStatusCodeFrom(st)
+}
+
+// StatusFromTwirpJSON returns the gRPC status held by a JSON error of the Twirp protocol, which is the
+// inverse of encoding a gRPC error using AsTwirpJSON. The reason and domain in the meta member are
+// added as an ErrorInfo, together with the rest of the meta member as its metadata. Unrecognized codes
+// are mapped to Unknown.
+func StatusFromTwirpJSON(data []byte) (*status.Status, error) {
+	st, _, err := statusFromTwirpJSON(data)
+	return st, err
+}
+
+// statusFromTwirpJSON is like StatusFromTwirpJSON, but it also returns whether the code was recognized.
+func statusFromTwirpJSON(data []byte) (*status.Status, bool, error) {
+	var twirpErr twirpError
+	if err := json.Unmarshal(data, &twirpErr); err != nil {
+		return nil, false, fmt.Errorf("could not unmarshal Twirp error: %w", err)
+	}
+	if twirpErr.Code == "" {
+		return nil, false, fmt.Errorf("invalid argument: Twirp error has no code")
+	}
+
+	code, recognized := gRPCCodesFromTwirp[twirpErr.Code]
+	if !recognized {
+		code = codes.Unknown
+	}
+
+	b := New(code).Message(twirpErr.Msg)
+	if len(twirpErr.Meta) > 0 {
+		errorInfo := &ErrorInfo{Reason: twirpErr.Meta["reason"], Domain: twirpErr.Meta["domain"]}
+		for k, v := range twirpErr.Meta {
+			if k == "reason" || k == "domain" {
+				continue
+			}
+			if errorInfo.Metadata == nil {
+				errorInfo.Metadata = make(map[string]string, len(twirpErr.Meta))
+			}
+			errorInfo.Metadata[k] = v
+		}
+		b.ErrorInfo(errorInfo)
+	}
+
+	return b.Status(), recognized, nil
+}
+
+// isTwirpJSON reports whether the JSON is a Twirp error rather than a Connect error. Both have a string
+// code, but only Twirp errors have a msg member.
+func isTwirpJSON(data []byte) bool {
+	var probe struct {
+		Msg *string `json:"msg"`
+	}
+	return json.Unmarshal(data, &probe) == nil && probe.Msg != nil
+}
@@ -0,0 +1,163 @@
+package grpcerr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tobbstr/testa/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestHttpResponseEncodeWriteAsTwirpJSON(t *testing.T) {
+	tests := []struct {
+		name           string
+		gRPCErr        error
+		wantStatusCode int
+		want           string
+	}{
+		{
+			name: "Should flatten ErrorInfo into meta",
+			gRPCErr: New(codes.NotFound).
+				Message("dummy-msg").
+				ErrorInfo(&ErrorInfo{Reason: "DUMMY_REASON", Domain: "dummy-domain", Metadata: map[string]string{"dummy-key": "dummy-value", "reason": "dummy-overridden"}}).
+				RequestInfo(&RequestInfo{RequestID: "dummy-request-id"}).
+				Err(),
+			wantStatusCode: http.StatusNotFound,
+			want:           `{"code": "not_found", "msg": "dummy-msg", "meta": {"reason": "DUMMY_REASON", "domain": "dummy-domain", "dummy-key": "dummy-value"}}`,
+		},
+		{
+			name:           "Should use Twirp HTTP status code when get FailedPrecondition",
+			gRPCErr:        New(codes.FailedPrecondition).Message("dummy-msg").Err(),
+			wantStatusCode: http.StatusPreconditionFailed,
+			want:           `{"code": "failed_precondition", "msg": "dummy-msg"}`,
+		},
+		{
+			name:           "Should use Twirp HTTP status code when get DeadlineExceeded",
+			gRPCErr:        New(codes.DeadlineExceeded).Message("dummy-msg").Err(),
+			wantStatusCode: http.StatusRequestTimeout,
+			want:           `{"code": "deadline_exceeded", "msg": "dummy-msg"}`,
+		},
+		{
+			name:           "Should use Twirp code when get DataLoss",
+			gRPCErr:        New(codes.DataLoss).Message("dummy-msg").Err(),
+			wantStatusCode: http.StatusInternalServerError,
+			want:           `{"code": "dataloss", "msg": "dummy-msg"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+			w := httptest.NewRecorder()
+
+			// When
+			err := NewHttpResponseEncodeWriter(w)(tt.gRPCErr).AsTwirpJSON()
+
+			// Then
+			assert(err).IsNil()
+			assert(w.Code).Equals(tt.wantStatusCode)
+			assert(w.Header().Get("Content-Type")).Equals("application/json")
+			assert(w.Body.String()).IsJSONEqualTo(tt.want)
+		})
+	}
+}
+
+func TestHttpResponseEncodeWriteAsTwirpJSONUsesMapping(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+	w := httptest.NewRecorder()
+	mapping := &HTTPStatusMapping{Codes: map[codes.Code]int{codes.FailedPrecondition: http.StatusBadRequest}}
+
+	// When
+	err := NewHttpResponseEncodeWriter(w)(New(codes.FailedPrecondition).Err()).MapStatus(mapping).AsTwirpJSON()
+
+	// Then
+	assert(err).IsNil()
+	assert(w.Code).Equals(http.StatusBadRequest)
+}
+
+func TestStatusFromTwirpJSON(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		wantErr       bool
+		wantCode      codes.Code
+		wantMsg       string
+		wantErrorInfo ErrorInfo
+	}{
+		{
+			name:          "should return status with ErrorInfo when get meta",
+			data:          `{"code": "not_found", "msg": "dummy-msg", "meta": {"reason": "DUMMY_REASON", "domain": "dummy-domain", "dummy-key": "dummy-value"}}`,
+			wantCode:      codes.NotFound,
+			wantMsg:       "dummy-msg",
+			wantErrorInfo: ErrorInfo{Reason: "DUMMY_REASON", Domain: "dummy-domain", Metadata: map[string]string{"dummy-key": "dummy-value"}},
+		},
+		{
+			name:     "should map malformed code to InvalidArgument",
+			data:     `{"code": "malformed", "msg": "dummy-msg"}`,
+			wantCode: codes.InvalidArgument,
+			wantMsg:  "dummy-msg",
+		},
+		{
+			name:     "should map bad_route code to Unimplemented",
+			data:     `{"code": "bad_route", "msg": "dummy-msg"}`,
+			wantCode: codes.Unimplemented,
+			wantMsg:  "dummy-msg",
+		},
+		{
+			name:     "should map dataloss code to DataLoss",
+			data:     `{"code": "dataloss", "msg": "dummy-msg"}`,
+			wantCode: codes.DataLoss,
+			wantMsg:  "dummy-msg",
+		},
+		{
+			name:     "should return Unknown status when get unrecognized code",
+			data:     `{"code": "dummy-code", "msg": "dummy-msg"}`,
+			wantCode: codes.Unknown,
+			wantMsg:  "dummy-msg",
+		},
+		{
+			name:    "should return error when get JSON without code",
+			data:    `{"msg": "dummy-msg"}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			assert := assert.New(t)
+
+			// When
+			st, err := StatusFromTwirpJSON([]byte(tt.data))
+
+			// Then
+			if tt.wantErr {
+				assert(err).IsNotNil()
+				return
+			}
+			assert(err).IsNil()
+			assert(st.Code()).Equals(tt.wantCode)
+			assert(st.Message()).Equals(tt.wantMsg)
+			assert(ErrorInfoFrom(st.Err())).Equals(tt.wantErrorInfo)
+		})
+	}
+}
+
+func TestFromHTTPResponseTwirpJSON(t *testing.T) {
+	// Given
+	assert := assert.New(t)
+	w := httptest.NewRecorder()
+	gRPCErr := New(codes.PermissionDenied).Message("dummy-msg").ErrorInfo(&ErrorInfo{Reason: "DUMMY_REASON"}).Err()
+	if err := NewHttpResponseEncodeWriter(w)(gRPCErr).AsTwirpJSON(); err != nil {
+		t.Fatal(err)
+	}
+
+	// When
+	got := FromHTTPResponse(w.Result())
+
+	// Then
+	assert(Code(got)).Equals(codes.PermissionDenied)
+	assert(Message(got)).Equals("dummy-msg")
+	assert(ErrorInfoFrom(got)).Equals(ErrorInfo{Reason: "DUMMY_REASON"})
+}